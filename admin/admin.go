@@ -0,0 +1,266 @@
+// Package admin provides an http.Handler to inspect and rotate one or many named
+// rotatorr Loggers without restarting the app. Mount it anywhere; for example:
+//
+//	mux.Handle("/logs/", http.StripPrefix("/logs", handler))
+//
+// Routes, all of which return JSON:
+//
+//	GET  /              - status of every logger.
+//	GET  /{name}        - status of one logger, including backup files and compression reports.
+//	POST /{name}/rotate - rotate one logger now, and return the rotation. Returns 409 Conflict
+//	                      when low disk space (LowSpaceActiveOnly) truncates the file instead.
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+)
+
+// DefaultReports is how many compression reports are kept for each logger, if MaxReports is 0.
+const DefaultReports = 20
+
+// Handler serves the admin routes. Use New() to get one.
+type Handler struct {
+	// MaxReports is how many recent compression reports are kept for each logger.
+	MaxReports int
+
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	loggers map[string]*rotatorr.Logger
+	reports map[string][]*Report
+}
+
+// Status is returned for each logger.
+type Status struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Age     string    `json:"age"`
+	Last    *Rotation `json:"lastRotation,omitempty"`
+	Backups []string  `json:"backups,omitempty"`
+	Reports []*Report `json:"reports,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Rotation is returned after a rotation, and as the last rotation in a Status.
+type Rotation struct {
+	OldFile string    `json:"oldFile"`
+	NewFile string    `json:"newFile"`
+	Size    int64     `json:"size"`
+	Reason  string    `json:"reason"`
+	Time    time.Time `json:"time"`
+}
+
+// Report is a compressor report that has been received.
+type Report struct {
+	OldFile string    `json:"oldFile"`
+	NewFile string    `json:"newFile"`
+	OldSize int64     `json:"oldSize"`
+	NewSize int64     `json:"newSize"`
+	Elapsed string    `json:"elapsed"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// These errors are returned in a Status.
+var (
+	errNotFound  = errors.New("logger not found")
+	errTruncated = errors.New("log file truncated instead of rotated: free disk space is low")
+)
+
+// New returns an empty Handler.
+func New() *Handler {
+	handler := &Handler{
+		mux:     http.NewServeMux(),
+		loggers: make(map[string]*rotatorr.Logger),
+		reports: make(map[string][]*Report),
+	}
+
+	handler.mux.HandleFunc("GET /{$}", handler.list)
+	handler.mux.HandleFunc("GET /{name}", handler.status)
+	handler.mux.HandleFunc("POST /{name}/rotate", handler.rotate)
+
+	return handler
+}
+
+// Add registers a logger with the provided name. Adding the same name again replaces the logger.
+func (h *Handler) Add(name string, logger *rotatorr.Logger) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.loggers[name] = logger
+}
+
+// Remove unregisters a logger, and removes its compression reports.
+func (h *Handler) Remove(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.loggers, name)
+	delete(h.reports, name)
+}
+
+// Report saves a compressor report for a logger name.
+func (h *Handler) Report(name string, report *compressor.Report) {
+	saved := &Report{
+		OldFile: report.OldFile,
+		NewFile: report.NewFile,
+		OldSize: report.OldSize,
+		NewSize: report.NewSize,
+		Elapsed: report.Elapsed.String(),
+		Time:    time.Now(),
+	}
+
+	if report.Error != nil {
+		saved.Error = report.Error.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	maxReports := h.MaxReports
+	if maxReports < 1 {
+		maxReports = DefaultReports
+	}
+
+	reports := append(h.reports[name], saved)
+	if len(reports) > maxReports {
+		reports = slices.Clone(reports[len(reports)-maxReports:])
+	}
+
+	h.reports[name] = reports
+}
+
+// Reporter returns a callback for compressor.CompressBackground that saves reports for a logger name.
+func (h *Handler) Reporter(name string) func(report *compressor.Report) {
+	return func(report *compressor.Report) { h.Report(name, report) }
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(resp, req)
+}
+
+// list returns the status of every logger, sorted by name.
+func (h *Handler) list(resp http.ResponseWriter, _ *http.Request) {
+	h.mu.Lock()
+	names := make([]string, 0, len(h.loggers))
+
+	for name := range h.loggers {
+		names = append(names, name)
+	}
+	h.mu.Unlock()
+
+	slices.Sort(names)
+
+	statuses := []*Status{}
+
+	for _, name := range names {
+		if status, err := h.getStatus(name, false); err == nil {
+			statuses = append(statuses, status)
+		}
+	}
+
+	reply(resp, http.StatusOK, statuses)
+}
+
+// status returns the status of one logger, with its backup files and compression reports.
+func (h *Handler) status(resp http.ResponseWriter, req *http.Request) {
+	status, err := h.getStatus(req.PathValue("name"), true)
+	if err != nil {
+		reply(resp, http.StatusNotFound, &Status{Name: req.PathValue("name"), Error: err.Error()})
+		return
+	}
+
+	reply(resp, http.StatusOK, status)
+}
+
+// rotate rotates one logger.
+func (h *Handler) rotate(resp http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+
+	h.mu.Lock()
+	logger := h.loggers[name]
+	h.mu.Unlock()
+
+	if logger == nil {
+		reply(resp, http.StatusNotFound, &Status{Name: name, Error: errNotFound.Error()})
+		return
+	}
+
+	rotation, err := logger.RotateNow()
+	if err != nil {
+		reply(resp, http.StatusInternalServerError, &Status{Name: name, Error: err.Error()})
+		return
+	}
+
+	if rotation == nil {
+		reply(resp, http.StatusConflict, &Status{Name: name, Error: errTruncated.Error()})
+		return
+	}
+
+	reply(resp, http.StatusOK, newRotation(rotation))
+}
+
+// getStatus returns the current status of a logger. Details adds backup files and reports.
+func (h *Handler) getStatus(name string, details bool) (*Status, error) {
+	h.mu.Lock()
+	logger := h.loggers[name]
+	reports := slices.Clone(h.reports[name])
+	h.mu.Unlock()
+
+	if logger == nil {
+		return nil, errNotFound
+	}
+
+	stats := logger.Stats()
+	status := &Status{
+		Name: name,
+		Path: stats.FilePath,
+		Size: stats.FileSize,
+		Age:  stats.FileAge.Round(time.Second).String(),
+		Last: newRotation(logger.LastRotation()),
+	}
+
+	if !details {
+		return status, nil
+	}
+
+	status.Reports = reports
+
+	var err error
+	if status.Backups, err = logger.Backups(); err != nil {
+		status.Error = err.Error()
+	}
+
+	return status, nil
+}
+
+// newRotation converts a rotatorr.Rotation into a Rotation for JSON.
+func newRotation(rotation *rotatorr.Rotation) *Rotation {
+	if rotation == nil {
+		return nil
+	}
+
+	return &Rotation{
+		OldFile: rotation.OldFile,
+		NewFile: rotation.NewFile,
+		Size:    rotation.Size,
+		Reason:  rotation.Reason.String(),
+		Time:    rotation.Time,
+	}
+}
+
+// reply writes a JSON response.
+func reply(resp http.ResponseWriter, code int, data any) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	_ = json.NewEncoder(resp).Encode(data)
+}
@@ -0,0 +1,113 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/admin"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/introtator"
+)
+
+// request sends a request to the handler and decodes the JSON reply.
+func request(t *testing.T, handler http.Handler, method, path string, data any) int {
+	t.Helper()
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(method, path, nil))
+	require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), data))
+
+	return resp.Code
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "app.log"),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	handler := admin.New()
+	handler.MaxReports = 2
+	handler.Add("app", logger)
+
+	_, err = logger.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	statuses := []*admin.Status{}
+	assert.Equal(http.StatusOK, request(t, handler, http.MethodGet, "/", &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(filepath.Join(dir, "app.log"), statuses[0].Path)
+	assert.EqualValues(6, statuses[0].Size)
+	assert.Nil(statuses[0].Last)
+
+	rotation := &admin.Rotation{}
+	assert.Equal(http.StatusOK, request(t, handler, http.MethodPost, "/app/rotate", rotation))
+	assert.Equal(filepath.Join(dir, "app.1.log"), rotation.NewFile)
+	assert.EqualValues(6, rotation.Size)
+	assert.Equal("manual", rotation.Reason)
+	assert.Equal(http.StatusOK, request(t, handler, http.MethodPost, "/app/rotate", rotation))
+
+	report := handler.Reporter("app")
+	for _, size := range []int64{1, 2, 3} {
+		report(&compressor.Report{OldFile: "file", OldSize: size})
+	}
+
+	report(&compressor.Report{OldFile: "bad", Error: errors.New("test error")}) //nolint:err113
+
+	status := &admin.Status{}
+	assert.Equal(http.StatusOK, request(t, handler, http.MethodGet, "/app", status))
+	assert.Equal([]string{filepath.Join(dir, "app.2.log"), filepath.Join(dir, "app.1.log")},
+		status.Backups, "backups must be oldest first")
+	assert.EqualValues(0, status.Size)
+	require.NotNil(t, status.Last)
+	require.Len(t, status.Reports, 2, "only MaxReports reports must be kept")
+	assert.EqualValues(3, status.Reports[0].OldSize)
+	assert.Equal("test error", status.Reports[1].Error)
+
+	assert.Equal(http.StatusNotFound, request(t, handler, http.MethodGet, "/nope", status))
+	assert.Equal(http.StatusNotFound, request(t, handler, http.MethodPost, "/nope/rotate", status))
+	assert.NotEmpty(status.Error)
+
+	handler.Remove("app")
+	assert.Equal(http.StatusOK, request(t, handler, http.MethodGet, "/", &statuses))
+	assert.Empty(statuses)
+}
+
+func TestRotateTruncated(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	// No file system has this much free space, so the active file is truncated instead of rotated.
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:     filepath.Join(t.TempDir(), "app.log"),
+		Rotatorr:     &introtator.Layout{},
+		MinFreeBytes: math.MaxUint64,
+		LowSpace:     rotatorr.LowSpaceActiveOnly,
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	handler := admin.New()
+	handler.Add("app", logger)
+
+	status := &admin.Status{}
+	assert.Equal(http.StatusConflict, request(t, handler, http.MethodPost, "/app/rotate", status))
+	assert.NotEmpty(status.Error)
+}
@@ -0,0 +1,216 @@
+package compressor
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Codec compresses and decompresses files. Gzip, Zlib and Flate are included, and
+// registered by default. Register third-party codecs (like zstd) with Register,
+// so the layouts recognize their suffix when discovering and renaming backups.
+type Codec interface {
+	Name() string   // Unique name, like "gzip".
+	Suffix() string // Appended to compressed file names, like ".gz".
+	// NewWriter returns a writer that compresses into w. The level is CompressLevel.
+	// Codecs should use their default level when the level is out of range.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+	// NewReader returns a reader that decompresses r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// ErrUnknownCodec is returned when a file name does not end with a registered suffix.
+var ErrUnknownCodec = errors.New("no codec registered for file suffix")
+
+// These are appended to a fileName to make the new compressed file name.
+const (
+	SuffixZlib  = ".zz"
+	SuffixFlate = ".deflate"
+)
+
+// These are the built-in codecs, using the Go standard library.
+//
+//nolint:gochecknoglobals
+var (
+	Gzip Codec = &stdCodec{
+		name:   "gzip",
+		suffix: SuffixGZ,
+		writer: func(w io.Writer, level int) (io.WriteCloser, error) {
+			gzw, err := gzip.NewWriterLevel(w, level)
+			if err == nil {
+				gzw.Comment = reflect.TypeFor[Report]().PkgPath()
+			}
+
+			return gzw, err //nolint:wrapcheck
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }, //nolint:wrapcheck
+	}
+	Zlib Codec = &stdCodec{
+		name:   "zlib",
+		suffix: SuffixZlib,
+		writer: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return zlib.NewWriterLevel(w, level) //nolint:wrapcheck
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) }, //nolint:wrapcheck
+	}
+	Flate Codec = &stdCodec{
+		name:   "flate",
+		suffix: SuffixFlate,
+		writer: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return flate.NewWriter(w, level) //nolint:wrapcheck
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) { return flate.NewReader(r), nil },
+	}
+)
+
+// DefaultCodec is used by Compress and every procedure that calls it.
+var DefaultCodec = Gzip //nolint:gochecknoglobals
+
+// codecs holds every registered Codec by name.
+//
+//nolint:gochecknoglobals
+var codecs = struct {
+	sync.RWMutex
+	list map[string]Codec
+}{list: map[string]Codec{"gzip": Gzip, "zlib": Zlib, "flate": Flate}}
+
+// stdCodec is a Codec built from the standard library.
+type stdCodec struct {
+	name   string
+	suffix string
+	writer func(w io.Writer, level int) (io.WriteCloser, error)
+	reader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// Name satisfies the Codec interface.
+func (s *stdCodec) Name() string {
+	return s.name
+}
+
+// Suffix satisfies the Codec interface.
+func (s *stdCodec) Suffix() string {
+	return s.suffix
+}
+
+// NewWriter satisfies the Codec interface.
+func (s *stdCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+
+	return s.writer(w, level)
+}
+
+// NewReader satisfies the Codec interface.
+func (s *stdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return s.reader(r)
+}
+
+// Register adds a Codec, or replaces the registered Codec with the same name.
+// Like expvar.Publish, this panics if the Codec's Name or Suffix is empty;
+// an empty Suffix would match every file name.
+func Register(codec Codec) {
+	if codec.Name() == "" || codec.Suffix() == "" {
+		panic(fmt.Sprintf("compressor: Register of %T with an empty Name or Suffix", codec))
+	}
+
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	codecs.list[codec.Name()] = codec
+}
+
+// Lookup returns the registered Codec with the provided name, or nil.
+func Lookup(name string) Codec { //nolint:ireturn
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	return codecs.list[name]
+}
+
+// Codecs returns every registered Codec, sorted by name.
+func Codecs() []Codec {
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	list := make([]Codec, 0, len(codecs.list))
+	for _, codec := range codecs.list {
+		list = append(list, codec)
+	}
+
+	slices.SortFunc(list, func(a, b Codec) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return list
+}
+
+// Suffix returns the suffix of the registered Codec a file name ends with, or
+// an empty string if the file is not compressed with a registered Codec.
+func Suffix(fileName string) string {
+	codec := Detect(fileName)
+	if codec == nil {
+		return ""
+	}
+
+	return codec.Suffix()
+}
+
+// Detect returns the registered Codec for a file name, by its suffix, or nil.
+// The longest matching suffix wins.
+func Detect(fileName string) Codec { //nolint:ireturn
+	var found Codec
+
+	for _, codec := range Codecs() {
+		if strings.HasSuffix(fileName, codec.Suffix()) &&
+			(found == nil || len(codec.Suffix()) > len(found.Suffix())) {
+			found = codec
+		}
+	}
+
+	return found
+}
+
+// Open opens a compressed file for reading, using the registered Codec for its suffix.
+// Closing the returned reader closes the file.
+func Open(fileName string) (io.ReadCloser, error) {
+	codec := Detect(fileName)
+	if codec == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, fileName)
+	}
+
+	file, err := Filer.OpenFile(fileName, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening compressed file: %w", err)
+	}
+
+	reader, err := codec.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s reader: %w", codec.Name(), err)
+	}
+
+	return &readCloser{ReadCloser: reader, file: file}, nil
+}
+
+// readCloser closes the decompressor and the file under it.
+type readCloser struct {
+	io.ReadCloser
+	file io.Closer
+}
+
+// Close closes the decompressor and the file.
+func (r *readCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err //nolint:wrapcheck
+}
@@ -0,0 +1,88 @@
+package compressor_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/compressor"
+)
+
+// nopCodec is a third-party Codec that does not compress anything.
+type nopCodec struct{}
+
+type nopWriter struct{ io.Writer }
+
+func (nopCodec) Name() string                                 { return "nop" }
+func (nopCodec) Suffix() string                               { return ".nop" }
+func (nopCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+func (nopCodec) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) {
+	return nopWriter{w}, nil
+}
+func (nopWriter) Close() error { return nil }
+
+// emptyCodec is missing its name or suffix.
+type emptyCodec struct {
+	nopCodec
+
+	name, suffix string
+}
+
+func (e emptyCodec) Name() string   { return e.name }
+func (e emptyCodec) Suffix() string { return e.suffix }
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestCodecs(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte("a compressible log line\na compressible log line\n")
+
+	compressor.Register(nopCodec{})
+	assert.Panics(func() { compressor.Register(emptyCodec{}) }, "a codec without a name or suffix must not register")
+	assert.Panics(func() { compressor.Register(emptyCodec{name: "empty"}) }, "a codec without a suffix must not register")
+	assert.Nil(compressor.Lookup("empty"))
+
+	for _, codec := range compressor.Codecs() {
+		fileName := filepath.Join(t.TempDir(), "codec.log")
+		require.NoError(t, os.WriteFile(fileName, data, 0o600))
+
+		report, err := compressor.CompressWith(fileName, codec)
+		require.NoError(t, err, codec.Name())
+		assert.Equal(fileName+codec.Suffix(), report.NewFile)
+		assert.EqualValues(len(data), report.OldSize)
+		assert.NoFileExists(fileName, "the old file must be deleted")
+
+		info, err := os.Stat(report.NewFile)
+		require.NoError(t, err)
+		assert.Equal(info.Size(), report.NewSize, "the new size must be the compressed size")
+		assert.Equal(codec.Suffix(), compressor.Suffix(report.NewFile))
+		assert.Equal(codec, compressor.Detect(report.NewFile))
+
+		reader, err := compressor.Open(report.NewFile)
+		require.NoError(t, err, codec.Name())
+		output, err := io.ReadAll(reader)
+		require.NoError(t, err, codec.Name())
+		require.NoError(t, reader.Close())
+		assert.Equal(data, output, "%s must decompress what it compressed", codec.Name())
+	}
+
+	assert.Equal([]string{"flate", "gzip", "nop", "zlib"}, names(compressor.Codecs()))
+	assert.Equal(compressor.Gzip, compressor.Lookup("gzip"))
+	assert.Nil(compressor.Lookup("nope"))
+	assert.Empty(compressor.Suffix("file.log"))
+
+	_, err := compressor.Open("file.log")
+	require.ErrorIs(t, err, compressor.ErrUnknownCodec)
+}
+
+// names returns the names of the codecs.
+func names(codecs []compressor.Codec) []string {
+	list := make([]string, len(codecs))
+	for idx, codec := range codecs {
+		list[idx] = codec.Name()
+	}
+
+	return list
+}
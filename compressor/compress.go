@@ -1,14 +1,17 @@
 // Package compressor provides a simple interface used for
 // a post-rotate Rotatorr hook that compresses files.
+// Files are gzipped by default; change DefaultCodec to use zlib, flate or a
+// registered third-party Codec.
 package compressor
 
 import (
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"reflect"
+	"path/filepath"
 	"time"
 
 	"golift.io/rotatorr/filer"
@@ -17,7 +20,7 @@ import (
 // SuffixGZ is appended to a fileName to make the new compressed file name.
 const SuffixGZ = ".gz"
 
-// CompressLevel sets the global compression level.
+// CompressLevel sets the global compression level. The built-in codecs accept -2 through 9.
 var CompressLevel = gzip.DefaultCompression //nolint:gochecknoglobals
 
 // Filer allows overriding os-file procedures.
@@ -34,23 +37,23 @@ type Report struct {
 	Error   error
 }
 
-// Compress gzips a file and returns a report. Blocks until finished.
+// Compress compresses a file with DefaultCodec (gzip) and returns a report. Blocks until finished.
 func Compress(fileName string) (*Report, error) {
+	return CompressWith(fileName, DefaultCodec)
+}
+
+// CompressWith compresses a file with the provided Codec and returns a report. Blocks until finished.
+func CompressWith(fileName string, codec Codec) (*Report, error) {
 	// fmt.Println("compressing", fileName)
 	report := &Report{
 		OldFile: fileName,
-		NewFile: fileName + SuffixGZ,
+		NewFile: fileName + codec.Suffix(),
 		OldSize: 0,
 		NewSize: 0,
 		Error:   nil,
 		Elapsed: 0,
 	}
 
-	level := CompressLevel
-	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
-		level = gzip.DefaultCompression
-	}
-
 	oldFile, err := Filer.Stat(report.OldFile)
 	if report.Error = err; report.Error != nil {
 		return report, fmt.Errorf("stating old file: %w", report.Error)
@@ -58,7 +61,7 @@ func Compress(fileName string) (*Report, error) {
 
 	report.OldSize = oldFile.Size()
 	start := time.Now()
-	report.NewSize, report.Error = compress(report.OldFile, report.NewFile, oldFile.Mode(), level)
+	report.NewSize, report.Error = compress(report.OldFile, report.NewFile, oldFile.Mode(), codec)
 	report.Elapsed = time.Since(start)
 
 	if report.Error != nil {
@@ -71,14 +74,16 @@ func Compress(fileName string) (*Report, error) {
 // CompressBackground runs a file compression in the background.
 // A report is sent to a provided callback function when compression finishes.
 // Avoid using this on files that may be renamed by another thread.
+// Every call starts a go routine; use a Pool to limit concurrent compressions.
 func CompressBackground(fileName string, cb func(report *Report)) {
-	go func() {
-		report, _ := Compress(fileName)
+	background.CompressBackground(fileName, cb)
+}
 
-		if cb != nil {
-			cb(report)
-		}
-	}()
+// Wait blocks until compressions started by the package-level *Background* procedures
+// finish, or the context is done. This waits for every Logger in the app; use a Group
+// or a Pool to wait for one Logger's compressions.
+func Wait(ctx context.Context) error {
+	return background.Wait(ctx)
 }
 
 // CompressWithLog is the same as Compress, except it writes a report log instead of returning it.
@@ -126,18 +131,19 @@ func Log(report *Report, printf func(msg string, fmt ...any)) {
 	}
 }
 
-// compress does the "hard" work: Open the old file, open the new file, create a gzip writer,
-// copy the writer to the new file, close all open file handles, and lastly delete the old file.
-func compress(oldFile, newFile string, mode os.FileMode, level int) (int64, error) {
+// compress does the "hard" work: Open the old file, create a hidden temp file next to the new
+// file, and copy the old file into it with a codec writer. Then sync and close the temp file,
+// rename it to the new file, and lastly delete the old file. A crash never leaves a partial
+// new file; Sweep removes the temp files it may leave instead. Returns the size of the new file.
+func compress(oldFile, newFile string, mode os.FileMode, codec Codec) (size int64, err error) {
 	var (
-		size     int64
-		err      error
-		ncf, gzf *os.File
+		ncf, czf *os.File
+		tempFile = TempName(newFile)
 	)
 
 	defer func() { // First, so it executes last.
 		if err != nil {
-			_ = Filer.Remove(newFile)
+			_ = Filer.Remove(tempFile)
 		} else {
 			_ = Filer.Remove(oldFile)
 		}
@@ -149,29 +155,61 @@ func compress(oldFile, newFile string, mode os.FileMode, level int) (int64, erro
 	}
 	defer ncf.Close()
 
-	gzf, err = Filer.OpenFile(newFile, os.O_CREATE|os.O_WRONLY, mode)
+	czf, err = Filer.OpenFile(tempFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
 	if err != nil {
-		return 0, fmt.Errorf("opening gz file: %w", err)
+		return 0, fmt.Errorf("opening %s file: %w", codec.Name(), err)
 	}
+	defer czf.Close()
 
-	defer func() {
-		_ = gzf.Close()
-		// Set size of new file.
-		fs, err := Filer.Stat(newFile)
-		if err == nil {
-			size = fs.Size()
-		}
-	}()
+	if size, err = copyCodec(czf, ncf, codec); err != nil {
+		return 0, fmt.Errorf("%s -> %s: %w", oldFile, newFile, err)
+	}
 
-	gzw, _ := gzip.NewWriterLevel(gzf, level)
-	defer gzw.Close()
+	if err = czf.Close(); err != nil {
+		return 0, fmt.Errorf("closing %s: %w", tempFile, err)
+	}
 
-	gzw.Comment = reflect.TypeFor[Report]().PkgPath()
+	if err = Filer.Rename(tempFile, newFile); err != nil {
+		return 0, fmt.Errorf("renaming %s file: %w", codec.Name(), err)
+	}
 
-	size, err = io.Copy(gzw, ncf)
+	syncDir(filepath.Dir(newFile))
+
+	return size, nil
+}
+
+// copyCodec compresses src into dst and syncs dst. Returns the size of dst.
+func copyCodec(dst *os.File, src io.Reader, codec Codec) (int64, error) {
+	czw, err := codec.NewWriter(dst, CompressLevel)
 	if err != nil {
-		return size, fmt.Errorf("%s -> %s: %w", oldFile, newFile, err)
+		return 0, fmt.Errorf("%s writer: %w", codec.Name(), err)
 	}
 
-	return size, nil
+	if _, err = io.Copy(czw, src); err != nil {
+		_ = czw.Close()
+		return 0, fmt.Errorf("compressing: %w", err)
+	}
+
+	if err = czw.Close(); err != nil {
+		return 0, fmt.Errorf("closing %s writer: %w", codec.Name(), err)
+	}
+
+	if err = dst.Sync(); err != nil {
+		return 0, fmt.Errorf("syncing: %w", err)
+	}
+
+	info, err := dst.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("stating: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// syncDir makes a rename durable. This is best effort; it does not work on every OS.
+func syncDir(dir string) {
+	if dirFile, err := Filer.OpenFile(dir, os.O_RDONLY, 0); err == nil {
+		_ = dirFile.Sync()
+		_ = dirFile.Close()
+	}
 }
@@ -35,3 +35,44 @@ func TestCompress(t *testing.T) {
 	// XXX: check report items.
 	_ = os.Remove(oFile.Name())
 }
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestWait(t *testing.T) {
+	oFile, err := os.Create(filepath.Join(t.TempDir(), "waitfile.log"))
+	require.NoError(t, err, "error creating test file: %v", err)
+	_, err = oFile.Write(make([]byte, 300000))
+	require.NoError(t, err, "error writing test file: %v", err)
+	require.NoError(t, oFile.Close())
+
+	var report *compressor.Report
+
+	compressor.CompressBackground(oFile.Name(), func(r *compressor.Report) { report = r })
+	require.NoError(t, compressor.Wait(t.Context()))
+	require.NotNil(t, report, "wait must block until the compression finishes")
+	require.NoError(t, report.Error)
+	assert.FileExists(t, oFile.Name()+compressor.SuffixGZ)
+}
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		group    compressor.Group
+		other    compressor.Group
+		fileName = filepath.Join(t.TempDir(), "group.log")
+		reports  = make(chan *compressor.Report, 1)
+	)
+
+	require.NoError(t, os.WriteFile(fileName, make([]byte, 300000), 0o600))
+	require.NoError(t, group.Wait(t.Context()), "an empty group must not block")
+
+	group.CompressBackground(fileName, func(r *compressor.Report) { reports <- r })
+	require.NoError(t, other.Wait(t.Context()), "a group must only wait for its own compressions")
+	require.NoError(t, group.Wait(t.Context()))
+	assert.Zero(group.Pending())
+
+	report := <-reports
+	require.NoError(t, report.Error)
+	assert.FileExists(fileName + compressor.SuffixGZ)
+}
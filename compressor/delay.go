@@ -0,0 +1,69 @@
+package compressor
+
+import "time"
+
+// Lister lists the backup files for a log file, oldest first.
+// Every included Layout satisfies this; it matches rotatorr.Lister.
+type Lister interface {
+	Backups(fileName string) ([]string, error)
+}
+
+// Delay is a post-rotate hook that leaves the newest backups uncompressed, so they are
+// easy to read and grep. On every rotation, each backup that is not among the newest Keep
+// backups, and was last modified more than Age ago, is passed to Compress. Backups that
+// already have a registered codec suffix are skipped. Set only Keep or only Age to use one.
+//
+//	layout := &introtator.Layout{FileCount: 10}
+//	layout.PostRotate = (&compressor.Delay{Lister: layout, Keep: 2}).PostRotate
+type Delay struct {
+	Lister Lister        // REQUIRED: The Layout, or anything that lists its backups, oldest first.
+	Keep   int           // Number of newest backups left uncompressed.
+	Age    time.Duration // Backups modified within this duration are left uncompressed.
+	// Compress is called for every backup that needs compression. Default: CompressPostRotate,
+	// which blocks until each file is compressed. A Pool's PostRotate also works, and skips files
+	// it's compressing; set the Pool as the Layout's Background too.
+	Compress func(fileName, newFile string)
+}
+
+// PostRotate satisfies the PostRotate hook in every Layout.
+// The new file is ignored; the Lister decides which files are compressed.
+func (d *Delay) PostRotate(fileName, _ string) {
+	compress := d.Compress
+	if compress == nil {
+		compress = CompressPostRotate
+	}
+
+	for _, backup := range d.Expired(fileName) {
+		compress(fileName, backup)
+	}
+}
+
+// Expired returns the uncompressed backups of a log file that are ready for compression, oldest first.
+// Errors listing the backups return nothing; they're tried again after the next rotation.
+func (d *Delay) Expired(fileName string) []string {
+	files, err := d.Lister.Backups(fileName)
+	if err != nil || len(files) <= d.Keep {
+		return nil
+	}
+
+	var (
+		expired []string
+		cutoff  = time.Now().Add(-d.Age)
+	)
+
+	for _, backup := range files[:len(files)-max(d.Keep, 0)] {
+		if Suffix(backup) != "" {
+			continue // already compressed.
+		}
+
+		if d.Age > 0 {
+			if info, err := Filer.Stat(backup); err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+		}
+
+		expired = append(expired, backup)
+	}
+
+	return expired
+}
@@ -0,0 +1,77 @@
+package compressor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/introtator"
+	"golift.io/rotatorr/timerotator"
+)
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestDelayKeep(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.log")
+	layout := &introtator.Layout{}
+	layout.PostRotate = (&compressor.Delay{Lister: layout, Keep: 2}).PostRotate
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	for range 4 {
+		require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+		newFile, err := layout.Rotate(fileName)
+		require.NoError(t, err)
+		layout.Post(fileName, newFile)
+	}
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{
+		filepath.Join(dir, "service.4.log.gz"),
+		filepath.Join(dir, "service.3.log.gz"),
+		filepath.Join(dir, "service.2.log"),
+		filepath.Join(dir, "service.1.log"),
+	}, backups, "only the newest two backups must be left uncompressed")
+}
+
+func TestDelayAge(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir      = t.TempDir()
+		fileName = filepath.Join(dir, "service.log")
+		layout   = &timerotator.Layout{}
+		posted   []string
+		delay    = &compressor.Delay{
+			Lister:   layout,
+			Age:      time.Hour,
+			Compress: func(_, newFile string) { posted = append(posted, newFile) },
+		}
+		old    = filepath.Join(dir, "service-2026-10-15T08-00-00.000.log")
+		oldGZ  = filepath.Join(dir, "service-2026-10-15T07-00-00.000.log.gz")
+		recent = filepath.Join(dir, "service-2026-10-16T08-00-00.000.log")
+	)
+
+	for _, name := range []string{fileName, old, oldGZ, recent} {
+		require.NoError(t, os.WriteFile(name, []byte("data"), 0o600))
+	}
+
+	for _, name := range []string{old, oldGZ} {
+		require.NoError(t, os.Chtimes(name, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+	}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	delay.PostRotate(fileName, recent)
+	assert.Equal([]string{old}, posted, "only uncompressed backups older than Age must be compressed")
+}
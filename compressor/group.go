@@ -0,0 +1,92 @@
+package compressor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// background tracks compressions started by the package-level *Background* procedures.
+var background Group //nolint:gochecknoglobals
+
+// Group tracks compressions running in the background, so they can be waited for.
+// Give each Logger its own Group (or Pool), and set it as the Layout's Background,
+// so Logger.Shutdown waits only for that Logger's files. The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	count int           // compressions running.
+	idle  chan struct{} // closed when count drops to zero.
+}
+
+// CompressBackground runs a file compression in the background, tracked by this Group.
+// A report is sent to a provided callback function when compression finishes.
+// Avoid using this on files that may be renamed by another thread.
+func (g *Group) CompressBackground(fileName string, cb func(report *Report)) {
+	g.add()
+
+	go func() {
+		defer g.done()
+
+		report, _ := Compress(fileName)
+
+		if cb != nil {
+			cb(report)
+		}
+	}()
+}
+
+// PostRotate satisfies the PostRotate hook in every Layout. The new file is compressed in the
+// background, and the report is written with the global logger. With the introtator package,
+// set the Group as the Layout's Background too, so backups are not renamed while they compress.
+func (g *Group) PostRotate(_, newFile string) {
+	g.CompressBackground(newFile, func(report *Report) { Log(report, nil) })
+}
+
+// Pending returns the number of compressions running.
+func (g *Group) Pending() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.count
+}
+
+// Wait blocks until every compression in the Group finishes, or the context is done.
+func (g *Group) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	idle := g.idle
+	count := g.count
+	g.mu.Unlock()
+
+	if count == 0 {
+		return nil
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for compression: %w", ctx.Err())
+	}
+}
+
+// add counts a new compression, and makes a new idle channel if it's the first.
+func (g *Group) add() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.count == 0 {
+		g.idle = make(chan struct{})
+	}
+
+	g.count++
+}
+
+// done counts a finished compression, and closes the idle channel if it was the last.
+func (g *Group) done() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.count--; g.count == 0 {
+		close(g.idle)
+	}
+}
@@ -0,0 +1,200 @@
+package compressor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// These defaults are used when PoolConfig.Workers or PoolConfig.Queue are omitted.
+const (
+	DefaultWorkers = 1
+	DefaultQueue   = 100
+)
+
+// ErrPoolClosed is returned when adding a file to a closed Pool.
+var ErrPoolClosed = errors.New("compression pool is closed")
+
+// PoolConfig is the data needed to create a compression Pool.
+type PoolConfig struct {
+	Workers int   // Number of files compressed at once. Default: 1
+	Queue   int   // Number of files waiting for a worker before Add blocks. Default: 100
+	Codec   Codec // Compression codec. Default: DefaultCodec
+	// OnReport is called after every compression, from the worker go routine.
+	OnReport func(report *Report)
+}
+
+// Pool compresses files with a fixed number of workers. When the queue is full, Add
+// blocks, so a burst of rotations slows down instead of starting dozens of compressions.
+// Adding a file that is already queued or compressing does nothing. Get one from NewPool.
+// Set the Pool as a Layout's Background too; the introtator package waits for it before
+// renaming backups, and Logger.Shutdown waits for it.
+type Pool struct {
+	config  *PoolConfig
+	jobs    chan string
+	done    chan struct{} // closed to stop the workers.
+	workers sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+	queued  map[string]struct{} // files queued or compressing.
+	idle    chan struct{}       // closed when nothing is queued or compressing.
+}
+
+// NewPool starts the workers and returns a Pool. Call Close to stop them.
+func NewPool(config *PoolConfig) *Pool {
+	if config.Workers < 1 {
+		config.Workers = DefaultWorkers
+	}
+
+	if config.Queue < 1 {
+		config.Queue = DefaultQueue
+	}
+
+	if config.Codec == nil {
+		config.Codec = DefaultCodec
+	}
+
+	pool := &Pool{
+		config: config,
+		jobs:   make(chan string, config.Queue),
+		done:   make(chan struct{}),
+		queued: make(map[string]struct{}),
+		idle:   make(chan struct{}),
+	}
+	close(pool.idle)
+
+	pool.workers.Add(config.Workers)
+
+	for range config.Workers {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// Add queues a file for compression. This blocks while the queue is full, until the
+// context is done. Files already queued or compressing are skipped. Wait and Close
+// wait for the files added here; the package-level Wait does not.
+func (p *Pool) Add(ctx context.Context, fileName string) error {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+
+	if _, ok := p.queued[fileName]; ok {
+		p.mu.Unlock()
+		return nil
+	}
+
+	if len(p.queued) == 0 {
+		p.idle = make(chan struct{})
+	}
+
+	p.queued[fileName] = struct{}{}
+	p.mu.Unlock()
+
+	select {
+	case p.jobs <- fileName:
+		return nil
+	case <-ctx.Done():
+		p.finish(fileName)
+		return fmt.Errorf("queueing %s: %w", fileName, ctx.Err())
+	}
+}
+
+// PostRotate satisfies the PostRotate hook in every Layout. The new file is queued for
+// compression, and this blocks while the queue is full. If the file cannot be queued,
+// the error is sent to OnReport.
+func (p *Pool) PostRotate(_, newFile string) {
+	if err := p.Add(context.Background(), newFile); err != nil && p.config.OnReport != nil {
+		p.config.OnReport(&Report{OldFile: newFile, Error: err})
+	}
+}
+
+// Pending returns the number of files queued or compressing.
+func (p *Pool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.queued)
+}
+
+// Wait blocks until every queued file is compressed, or the context is done.
+func (p *Pool) Wait(ctx context.Context) error {
+	p.mu.Lock()
+	idle := p.idle
+	p.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for compression pool: %w", ctx.Err())
+	}
+}
+
+// Close stops accepting files, waits for the queue to drain, and stops the workers.
+// If the context is done first, the workers keep draining the queue in the background.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+
+	p.closed = true
+	p.mu.Unlock()
+
+	go func() {
+		_ = p.Wait(context.Background())
+		close(p.done)
+	}()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		p.workers.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("closing compression pool: %w", ctx.Err())
+	}
+}
+
+// work compresses files from the queue until the pool is closed and drained.
+func (p *Pool) work() {
+	defer p.workers.Done()
+
+	for {
+		select {
+		case fileName := <-p.jobs:
+			report, _ := CompressWith(fileName, p.config.Codec)
+			if p.config.OnReport != nil {
+				p.config.OnReport(report)
+			}
+
+			p.finish(fileName)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// finish removes a file from the queue, and marks the pool idle if it was the last one.
+func (p *Pool) finish(fileName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.queued, fileName)
+
+	if len(p.queued) == 0 {
+		close(p.idle)
+	}
+}
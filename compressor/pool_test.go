@@ -0,0 +1,128 @@
+package compressor_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/compressor"
+)
+
+// slowCodec tracks how many files are compressed at once.
+type slowCodec struct {
+	nopCodec
+
+	gate   chan struct{} // blocks compressions until closed, if not nil.
+	active atomic.Int32
+	most   atomic.Int32
+}
+
+func (s *slowCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if active := s.active.Add(1); active > s.most.Load() {
+		s.most.Store(active)
+	}
+
+	if s.gate != nil {
+		<-s.gate
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	s.active.Add(-1)
+
+	return s.nopCodec.NewWriter(w, level)
+}
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestPool(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		dir     = t.TempDir()
+		codec   = &slowCodec{}
+		mu      sync.Mutex
+		reports []*compressor.Report
+	)
+
+	pool := compressor.NewPool(&compressor.PoolConfig{
+		Workers: 2,
+		Queue:   1,
+		Codec:   codec,
+		OnReport: func(report *compressor.Report) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			reports = append(reports, report)
+		},
+	})
+
+	for idx := range 6 {
+		fileName := filepath.Join(dir, "file"+string(rune('a'+idx))+".log")
+		require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+		require.NoError(t, pool.Add(t.Context(), fileName))
+		require.NoError(t, pool.Add(t.Context(), fileName), "duplicates must be skipped")
+	}
+
+	pool.PostRotate("", filepath.Join(dir, "filea.log")) // may or may not be a duplicate.
+	require.NoError(t, pool.Wait(t.Context()))
+	assert.Zero(pool.Pending())
+	assert.LessOrEqual(codec.most.Load(), int32(2), "no more than Workers files may compress at once")
+
+	for idx := range 6 {
+		assert.FileExists(filepath.Join(dir, "file"+string(rune('a'+idx))+".log.nop"))
+	}
+
+	mu.Lock()
+	assert.GreaterOrEqual(len(reports), 6)
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	require.NoError(t, pool.Close(ctx))
+	require.ErrorIs(t, pool.Add(ctx, "file"), compressor.ErrPoolClosed)
+	require.ErrorIs(t, pool.Close(ctx), compressor.ErrPoolClosed)
+
+	pool.PostRotate("", "file")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.ErrorIs(t, reports[len(reports)-1].Error, compressor.ErrPoolClosed)
+}
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestPoolBackpressure(t *testing.T) {
+	dir := t.TempDir()
+	codec := &slowCodec{gate: make(chan struct{})}
+	pool := compressor.NewPool(&compressor.PoolConfig{Codec: codec, Queue: 1})
+
+	for _, name := range []string{"one", "two", "three"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o600))
+	}
+
+	require.NoError(t, pool.Add(t.Context(), filepath.Join(dir, "one")))
+
+	for codec.active.Load() == 0 { // wait for the worker to take it.
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, pool.Add(t.Context(), filepath.Join(dir, "two")), "the queue has room for one")
+
+	// A full queue must block until the context is done.
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, pool.Add(ctx, filepath.Join(dir, "three")), context.DeadlineExceeded)
+	assert.Equal(t, 2, pool.Pending())
+
+	close(codec.gate)
+	require.NoError(t, pool.Close(t.Context()))
+	assert.FileExists(t, filepath.Join(dir, "two.nop"))
+	assert.FileExists(t, filepath.Join(dir, "three"), "the file that was not queued must not be compressed")
+}
@@ -0,0 +1,94 @@
+package compressor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golift.io/rotatorr/filer"
+)
+
+// TempSuffix is appended to the hidden temp files written during compression.
+const TempSuffix = ".tmp"
+
+// ErrSweep is returned when Sweep cannot read a directory or remove a temp file.
+var ErrSweep = errors.New("sweeping temp files")
+
+// TempName returns the hidden temp file name used while compressing to newFile.
+// This is in the same directory, so it can be renamed into place.
+func TempName(newFile string) string {
+	return filepath.Join(filepath.Dir(newFile), "."+filepath.Base(newFile)+TempSuffix)
+}
+
+// Sweep removes orphaned compression temp files from the provided directories.
+// These are left behind when the app exits during a compression. Every temp file
+// for a registered codec is removed; use SweepFunc to remove only your own files.
+// Returns the files that were removed. Errors removing a file do not stop the sweep.
+func Sweep(dirs ...string) ([]string, error) {
+	var removed, errs []string
+
+	for _, dir := range dirs {
+		files, dirErrs := sweep(Filer, dir, nil)
+		removed = append(removed, files...)
+		errs = append(errs, dirErrs...)
+	}
+
+	return removed, sweepError(errs)
+}
+
+// SweepFunc removes orphaned compression temp files from one directory using the provided Filer.
+// Only temp files whose compressed file name (the temp name without the leading dot and TempSuffix)
+// passes match are removed. The included layouts call this from Dirs at startup with a match that
+// only accepts their own backups. A nil match removes every temp file for a registered codec.
+func SweepFunc(fs filer.Filer, dir string, match func(name string) bool) ([]string, error) {
+	removed, errs := sweep(fs, dir, match)
+
+	return removed, sweepError(errs)
+}
+
+func sweep(fs filer.Filer, dir string, match func(name string) bool) ([]string, []string) {
+	files, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	var removed, errs []string
+
+	for _, file := range files {
+		name, ok := tempTarget(file.Name())
+		if !ok || (match != nil && !match(name)) {
+			continue
+		}
+
+		fileName := filepath.Join(dir, file.Name())
+		if err := fs.Remove(fileName); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		removed = append(removed, fileName)
+	}
+
+	return removed, errs
+}
+
+func sweepError(errs []string) error {
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrSweep, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// tempTarget returns the compressed file name a temp file was written for, and true
+// if the name looks like a compression temp file for a registered codec.
+func tempTarget(name string) (string, bool) {
+	if !strings.HasPrefix(name, ".") || !strings.HasSuffix(name, TempSuffix) {
+		return "", false
+	}
+
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "."), TempSuffix)
+
+	return name, Suffix(name) != ""
+}
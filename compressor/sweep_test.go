@@ -0,0 +1,75 @@
+package compressor_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/filer"
+)
+
+var errTest = errors.New("this is a test error")
+
+// failCodec fails in the middle of writing a compressed file.
+type failCodec struct{ nopCodec }
+
+type failWriter struct{ io.Writer }
+
+func (failCodec) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) { return failWriter{w}, nil }
+func (f failWriter) Close() error                                      { return nil }
+func (f failWriter) Write(b []byte) (int, error) {
+	_, _ = f.Writer.Write(b[:len(b)/2])
+	return len(b) / 2, errTest
+}
+
+func TestSweep(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	for _, name := range []string{".a.log.gz.tmp", ".b.log.tmp", "c.log.gz.tmp", "d.log.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	removed, err := compressor.Sweep(dir, filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, compressor.ErrSweep, "a missing directory must be an error")
+	assert.Equal([]string{filepath.Join(dir, ".a.log.gz.tmp")}, removed, "only hidden codec temp files must be removed")
+	assert.FileExists(filepath.Join(dir, ".b.log.tmp"))
+	assert.FileExists(filepath.Join(dir, "c.log.gz.tmp"))
+	assert.FileExists(filepath.Join(dir, "d.log.gz"))
+
+	for _, name := range []string{".a.log.gz.tmp", ".e.log.gz.tmp"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	removed, err = compressor.SweepFunc(filer.Default(), dir, func(name string) bool { return name == "e.log.gz" })
+	require.NoError(t, err)
+	assert.Equal([]string{filepath.Join(dir, ".e.log.gz.tmp")}, removed, "only matching temp files must be removed")
+	assert.FileExists(filepath.Join(dir, ".a.log.gz.tmp"))
+}
+
+//nolint:paralleltest // TestCompress changes the global compression level.
+func TestCompressFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	fileName := filepath.Join(t.TempDir(), "fail.log")
+	require.NoError(t, os.WriteFile(fileName, []byte("some data"), 0o600))
+
+	report, err := compressor.CompressWith(fileName, failCodec{})
+	require.ErrorIs(t, err, errTest)
+	require.ErrorIs(t, report.Error, errTest)
+	assert.FileExists(fileName, "the source must be kept when compression fails")
+	assert.NoFileExists(report.NewFile, "a partial compressed file must never exist")
+	assert.NoFileExists(compressor.TempName(report.NewFile), "the temp file must be removed")
+
+	report, err = compressor.Compress(fileName)
+	require.NoError(t, err)
+	assert.NoFileExists(fileName)
+	assert.FileExists(report.NewFile)
+	assert.NoFileExists(compressor.TempName(report.NewFile))
+}
@@ -0,0 +1,151 @@
+package rotatorr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// copyTruncate rotates the active file without closing it. The active file is moved
+// aside, copied back to Config.Filepath, and the copy is passed to the Rotatorr.
+// Then the active file is moved back and truncated. Open file descriptors in other
+// processes follow the moved file, so they keep writing to the active log file.
+func (l *Logger) copyTruncate(reason Reason) error {
+	size := l.size
+
+	err := l.flush()
+	if err == nil && l.config.SyncPolicy != SyncNever {
+		err = l.sync()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	stash := l.stashName()
+
+	if err = l.Rename(l.config.Filepath, stash); err != nil {
+		return fmt.Errorf("moving log file for copytruncate: %w", err)
+	}
+
+	fpath, err := l.copyAndRotate(stash)
+	if fpath != "" {
+		defer l.rotatorrPost(fpath)
+	}
+
+	// Put the active file back where it belongs, replacing the copy if rotation failed.
+	if renameErr := l.Rename(stash, l.config.Filepath); renameErr != nil {
+		// The open file is stuck at the stash name; close it so the next write reopens Filepath.
+		_ = l.File.Close()
+		l.File = nil
+
+		return fmt.Errorf("restoring log file after copytruncate: %w", renameErr)
+	}
+
+	if l.rotated(fpath, size, reason, err); err != nil {
+		return err
+	}
+
+	if l.config.SyncPolicy != SyncNever {
+		l.syncDirs(l.config.Filepath, fpath)
+	}
+
+	if err = l.File.Truncate(0); err != nil {
+		return fmt.Errorf("truncating log file %s: %w", l.config.Filepath, err)
+	}
+
+	l.size = 0
+	l.unsynced = 0
+	l.created = time.Now()
+
+	return nil
+}
+
+// stashName returns the name the active file is moved to during copyTruncate.
+func (l *Logger) stashName() string {
+	return filepath.Join(filepath.Dir(l.config.Filepath), "."+filepath.Base(l.config.Filepath)+".copytruncate")
+}
+
+// recoverStash moves the active file back from its stash name at startup. The stash is left
+// behind when the app exits during copyTruncate, or when moving it back failed. Other processes
+// may still write to it. If Filepath exists too, it is appended to the stash first, so nothing
+// is lost; a partial copy made before the app exited is duplicated.
+func (l *Logger) recoverStash() error {
+	stash := l.stashName()
+	if _, err := l.Stat(stash); err != nil {
+		return nil //nolint:nilerr // no stash, nothing to recover.
+	}
+
+	if _, err := l.Stat(l.config.Filepath); err == nil {
+		if err := l.appendFile(stash, l.config.Filepath); err != nil {
+			return err
+		}
+	}
+
+	if err := l.Rename(stash, l.config.Filepath); err != nil {
+		return fmt.Errorf("restoring log file after copytruncate: %w", err)
+	}
+
+	return nil
+}
+
+// appendFile appends the contents of src to dst.
+func (l *Logger) appendFile(dst, src string) error {
+	srcFile, err := l.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return fmt.Errorf("opening log file to restore copytruncate: %w", err)
+	}
+	defer srcFile.Close()
+
+	dstFile, err := l.OpenFile(dst, os.O_WRONLY|os.O_APPEND, l.config.FileMode)
+	if err != nil {
+		return fmt.Errorf("opening copytruncate stash: %w", err)
+	}
+
+	_, err = io.Copy(dstFile, srcFile)
+	if closeErr := dstFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("restoring log file after copytruncate: %w", err)
+	}
+
+	return nil
+}
+
+// copyAndRotate copies the stashed active file to Config.Filepath and asks the Rotatorr to rotate it.
+func (l *Logger) copyAndRotate(stash string) (string, error) {
+	src, err := l.OpenFile(stash, os.O_RDONLY, 0)
+	if err != nil {
+		return "", fmt.Errorf("opening log file for copytruncate: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := l.OpenFile(l.config.Filepath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, l.config.FileMode)
+	if err != nil {
+		return "", fmt.Errorf("creating log file copy: %w", err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if err == nil && l.config.SyncPolicy != SyncNever {
+		err = dst.Sync()
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("copying log file: %w", err)
+	}
+
+	fpath, err := l.rotatorrRotate()
+	if err != nil {
+		return fpath, fmt.Errorf("error rotatorring: %w", err)
+	}
+
+	return fpath, nil
+}
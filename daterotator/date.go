@@ -0,0 +1,263 @@
+// Package daterotator provides an interface for Rotatorr that renames backup log
+// files with a date and an integer counter in the name. The counter starts at 1
+// every day, and older files are never renamed. By default rotated log files are
+// named: service-2006-01-02.1.log. Control the date format with Layout.Format.
+// Backups are sorted by date, then by counter, so the order is correct across days.
+package daterotator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/filer"
+	"golift.io/rotatorr/internal/backups"
+)
+
+// Layout defines how date-and-counter backup logs have their file names decided.
+type Layout struct {
+	filer.Filer
+
+	ArchiveDir string        // Location where rotated backup logs are moved to.
+	FileCount  int           // Maximum number of rotated log files.
+	FileAge    time.Duration // Maximum age of rotated files. Measured from the start of the day in the name.
+	TotalSize  int64         // Maximum total size of rotated files (compressed or not), in bytes.
+	// Retention is a custom policy applied with FileCount, FileAge and TotalSize. Any of them
+	// may delete a file. Backup times are the start of the day in the file name.
+	Retention rotatorr.Retention
+	UseUTC    bool   // Sets the time zone to UTC when writing dates (backup files).
+	Format    string // Format for Go Time. Should only contain a date. Default: 2006-01-02
+	Joiner    string // The string between the file name prefix and date. Default: -
+	Extension string // Backup file extension, like ".jsonl". Default: the log file's extension.
+	// Recover passes backups that lack a compression suffix to PostRotate when Dirs is called at
+	// startup. This finishes compressions that were queued or running when the app exited.
+	// Only set this when PostRotate compresses files.
+	Recover bool
+	// Background is the background work started by PostRotate, like a compressor.Group or
+	// compressor.Pool. Wait waits for it, so Logger.Shutdown waits for this Logger's compressions.
+	Background rotatorr.Waiter
+	// Mockable interfaces. Can be used for custom processing. Setting these is very optional.
+	PostRotate func(fileName, newFile string)
+	deleted    func(fileName string, err error) // set by NotifyDelete.
+}
+
+// Some constant this package uses; not really needed externally.
+const (
+	FormatDefault = "2006-01-02"
+	DefaultJoiner = "-"
+	Counter       = "." // joins the date with the counter.
+)
+
+// Rotate forces the log to rotate immediately. Returns the new name of the rotated log.
+func (l *Layout) Rotate(fileName string) (string, error) {
+	var (
+		now     = l.now()
+		date    = now.Format(l.Format)
+		prefix  = l.getPrefix(fileName)
+		index   = 1
+		dir     = l.getArchiveDir(fileName)
+		backups = l.getAllLogFiles(fileName)
+	)
+
+	// The counter continues from the highest one with today's date.
+	for _, value := range backups.value {
+		if value.date.Format(l.Format) == date && value.index >= index {
+			index = value.index + 1
+		}
+	}
+
+	newFile := filepath.Join(dir, prefix+date+Counter+strconv.Itoa(index)+l.getExt(fileName))
+
+	err := l.Rename(fileName, newFile)
+	if err != nil {
+		return "", fmt.Errorf("error renaming log: %w", err)
+	}
+
+	return newFile, l.deleteOldLogs(l.getAllLogFiles(fileName))
+}
+
+// Dirs validates input data and returns the list of directories being used.
+func (l *Layout) Dirs(fileName string) ([]string, error) {
+	if l.Format == "" {
+		l.Format = FormatDefault
+	}
+
+	if l.Joiner == "" {
+		l.Joiner = DefaultJoiner
+	}
+
+	if l.Filer == nil {
+		l.Filer = filer.Default()
+	}
+
+	l.config().Start(l.getArchiveDir(fileName), fileName, l, l.isBackup(fileName))
+
+	return backups.Dirs(fileName, l.ArchiveDir), nil
+}
+
+// Post satisfies the Rotatorr interface.
+func (l *Layout) Post(fileName, newFile string) {
+	l.config().Post(fileName, newFile)
+}
+
+// NotifyDelete satisfies the rotatorr.DeleteNotifier interface.
+// The hook is called after every attempt to delete an old backup file.
+func (l *Layout) NotifyDelete(hook func(fileName string, err error)) {
+	l.deleted = hook
+}
+
+// Backups satisfies the rotatorr.Lister interface.
+// Returns the backup files for a log file, oldest first.
+func (l *Layout) Backups(fileName string) ([]string, error) {
+	return l.getAllLogFiles(fileName).Files, nil
+}
+
+// Prune satisfies the rotatorr.Pruner interface.
+// Deletes backup files, oldest first, until enough returns true.
+func (l *Layout) Prune(fileName string, enough func() bool) error {
+	return l.config().Prune(l.getAllLogFiles(fileName).Files, enough) //nolint:wrapcheck
+}
+
+// Wait satisfies the rotatorr.Waiter interface. This waits for the Background work
+// started by PostRotate, so Logger.Shutdown can wait for it to finish.
+func (l *Layout) Wait(ctx context.Context) error {
+	return l.config().Wait(ctx) //nolint:wrapcheck
+}
+
+// config returns the settings shared with the other layouts.
+func (l *Layout) config() *backups.Config {
+	return &backups.Config{
+		Filer:      l.Filer,
+		FileCount:  l.FileCount,
+		FileAge:    l.FileAge,
+		TotalSize:  l.TotalSize,
+		Retention:  l.Retention,
+		Recover:    l.Recover,
+		Background: l.Background,
+		PostRotate: l.PostRotate,
+		Deleted:    l.deleted,
+	}
+}
+
+// now returns the current time in the configured time zone.
+func (l *Layout) now() time.Time {
+	if l.UseUTC {
+		return time.Now().UTC()
+	}
+
+	return time.Now()
+}
+
+func (l *Layout) getArchiveDir(fileName string) string {
+	return backups.ArchiveDir(fileName, l.ArchiveDir)
+}
+
+// getPrefix returns the expected - or created - prefix on our log files.
+// The log file's extension is removed; backups get getExt instead.
+func (l *Layout) getPrefix(fileName string) string {
+	return backups.Prefix(fileName, l.Joiner)
+}
+
+// getExt returns the extension for backup files: Extension, or the log file's extension.
+func (l *Layout) getExt(fileName string) string {
+	return backups.Ext(fileName, l.Extension)
+}
+
+// deleteOldLogs deletes the backup files chosen by the retention policy.
+// Backup times are the start of the day in the file names.
+func (l *Layout) deleteOldLogs(logFiles *backupFiles) error {
+	var (
+		config = l.config()
+		times  = make([]time.Time, len(logFiles.value))
+	)
+
+	for idx, value := range logFiles.value {
+		times[idx] = value.date
+	}
+
+	return config.Delete(config.Policy().Expired(config.Backups(logFiles.Files, times))) //nolint:wrapcheck
+}
+
+// getAllLogFiles finds all the backup log files that match our date format and a counter.
+func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
+	var (
+		list   = &backupFiles{Files: []string{}, value: []stamp{}}
+		dir    = l.getArchiveDir(fileName)
+		prefix = l.getPrefix(fileName)
+		ext    = l.getExt(fileName)
+	)
+
+	fileList, err := l.ReadDir(dir)
+	if err != nil || len(fileList) == 0 {
+		return list
+	}
+
+	for idx := range fileList {
+		name := fileList[idx].Name()
+		if value, ok := l.parse(name, prefix, ext); ok {
+			list.Files = append(list.Files, filepath.Join(dir, name))
+			list.value = append(list.value, value)
+		}
+	}
+
+	sort.Sort(list)
+
+	return list
+}
+
+// parse returns the date and counter in a backup file name, and false if the name is not one of our backups.
+func (l *Layout) parse(name, prefix, ext string) (stamp, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return stamp{}, false // not our file.
+	}
+
+	location := time.Local
+	if l.UseUTC {
+		location = time.UTC
+	}
+
+	part := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressor.Suffix(name))
+	part = strings.TrimSuffix(part, ext)
+
+	split := strings.LastIndex(part, Counter)
+	if split < 0 {
+		return stamp{}, false // not our file.
+	}
+
+	date, err := time.ParseInLocation(l.Format, part[:split], location)
+	if err != nil {
+		return stamp{}, false // not our file.
+	}
+
+	index, err := strconv.Atoi(part[split+len(Counter):])
+	if err != nil || index < 1 {
+		return stamp{}, false // not our file.
+	}
+
+	return stamp{date: date, index: index}, true
+}
+
+// isBackup returns a function that reports if a file name, without a path, is a backup of fileName.
+func (l *Layout) isBackup(fileName string) func(name string) bool {
+	prefix, ext := l.getPrefix(fileName), l.getExt(fileName)
+
+	return func(name string) bool {
+		_, ok := l.parse(name, prefix, ext)
+		return ok
+	}
+}
+
+// Our interface must satify a rotatorr.Rotatorr and the optional interfaces.
+var (
+	_ rotatorr.Rotatorr       = (*Layout)(nil)
+	_ rotatorr.Waiter         = (*Layout)(nil)
+	_ rotatorr.DeleteNotifier = (*Layout)(nil)
+	_ rotatorr.Lister         = (*Layout)(nil)
+	_ rotatorr.Pruner         = (*Layout)(nil)
+)
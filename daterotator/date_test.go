@@ -0,0 +1,86 @@
+package daterotator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/daterotator"
+)
+
+func TestRotate(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.log")
+	layout := &daterotator.Layout{FileCount: 5, UseUTC: true}
+
+	dirs, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{dir}, dirs)
+
+	for _, name := range []string{
+		"service-2020-01-01.1.log.gz", "service-2020-01-01.10.log", "service-2020-01-01.2.log",
+		"service-2019-12-31.5.log", "service-2020-01-01.log", "other-2020-01-01.1.log",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("backup"), 0o600))
+	}
+
+	today := time.Now().UTC().Format(daterotator.FormatDefault)
+
+	for idx, counter := range []string{"1", "2"} {
+		require.NoError(t, os.WriteFile(fileName, []byte("active"), 0o600))
+
+		newFile, err := layout.Rotate(fileName)
+		require.NoError(t, err)
+		assert.Equal(filepath.Join(dir, "service-"+today+"."+counter+".log"), newFile, "rotation %d", idx)
+	}
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{
+		filepath.Join(dir, "service-2020-01-01.1.log.gz"),
+		filepath.Join(dir, "service-2020-01-01.2.log"),
+		filepath.Join(dir, "service-2020-01-01.10.log"),
+		filepath.Join(dir, "service-"+today+".1.log"),
+		filepath.Join(dir, "service-"+today+".2.log"),
+	}, backups, "backups must sort by date then counter, and the oldest beyond FileCount must be deleted")
+	assert.FileExists(filepath.Join(dir, "service-2020-01-01.log"), "files without a counter are not ours")
+	assert.FileExists(filepath.Join(dir, "other-2020-01-01.1.log"), "files with another prefix are not ours")
+}
+
+func TestSweep(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir    = t.TempDir()
+		temp   = filepath.Join(dir, ".service-2026-10-16.1.log.gz.tmp")
+		others = []string{
+			filepath.Join(dir, ".someoneelse.tar.gz.tmp"),
+			filepath.Join(dir, ".service-notadate.1.log.gz.tmp"),
+			filepath.Join(dir, ".other-2026-10-16.1.log.gz.tmp"),
+		}
+	)
+
+	for _, fileName := range append(others, temp) {
+		require.NoError(t, os.WriteFile(fileName, []byte("partial"), 0o600))
+	}
+
+	_, err := (&daterotator.Layout{}).Dirs(filepath.Join(dir, "service.log"))
+	require.NoError(t, err)
+	assert.FileExists(temp, "nothing must be swept without PostRotate or Recover")
+
+	layout := &daterotator.Layout{PostRotate: func(_, _ string) {}}
+	_, err = layout.Dirs(filepath.Join(dir, "service.log"))
+	require.NoError(t, err)
+	assert.NoFileExists(temp, "orphaned compression temp files must be swept on startup")
+
+	for _, fileName := range others {
+		assert.FileExists(fileName, "temp files for other backups must be kept")
+	}
+}
@@ -0,0 +1,42 @@
+package daterotator
+
+import (
+	"sort"
+	"time"
+)
+
+// stamp is the date and counter parsed out of a backup file name.
+type stamp struct {
+	date  time.Time
+	index int
+}
+
+// backupFiles is used to satisfy a sort.Sort interface.
+type backupFiles struct {
+	Files []string
+	value []stamp
+}
+
+// Len is part of sort.Interface.
+func (b *backupFiles) Len() int {
+	return len(b.Files)
+}
+
+// Swap is part of sort.Interface. We track two slices, so swap them both!
+func (b *backupFiles) Swap(i, j int) {
+	b.Files[i], b.Files[j] = b.Files[j], b.Files[i]
+	b.value[i], b.value[j] = b.value[j], b.value[i]
+}
+
+// Less is part of the sort.Sort interface.
+// The files are sorted by date, then by counter, so the oldest files are first.
+func (b *backupFiles) Less(i, j int) bool {
+	if !b.value[i].date.Equal(b.value[j].date) {
+		return b.value[i].date.Before(b.value[j].date)
+	}
+
+	return b.value[i].index < b.value[j].index
+}
+
+// Our backupFiles interface must satify a sort.Interface.
+var _ sort.Interface = (*backupFiles)(nil)
@@ -0,0 +1,116 @@
+package rotatorr
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// LowSpace decides what happens to writes while free disk space is below Config.MinFreeBytes.
+type LowSpace uint8
+
+// These are the low disk space behaviors.
+const (
+	// LowSpaceDrop discards writes and returns ErrLowSpace.
+	LowSpaceDrop LowSpace = iota
+	// LowSpaceStderr writes to stderr instead of the log file.
+	LowSpaceStderr
+	// LowSpaceActiveOnly keeps writing the active file, but rotating it truncates it instead,
+	// so no new backups are created. Backups are already pruned as far as the Rotatorr allows.
+	LowSpaceActiveOnly
+)
+
+// DefaultSpaceCheck is used when MinFreeBytes is set and SpaceCheck is omitted.
+const DefaultSpaceCheck = 10 * time.Second
+
+// checkSpace compares the free space on every log directory to Config.MinFreeBytes.
+// When space is low, the oldest backups are pruned if the Rotatorr is a Pruner.
+// An event is sent when space runs low, and again when it recovers.
+func (l *Logger) checkSpace() {
+	if l.config.MinFreeBytes == 0 {
+		return
+	}
+
+	free, low := l.freeSpace()
+
+	if pruner, ok := l.Interface.(Pruner); ok && low {
+		err := pruner.Prune(l.config.Filepath, func() bool {
+			free, low = l.freeSpace()
+			return !low
+		})
+		if err != nil {
+			l.event(&Event{Type: EventLowSpace, File: l.config.Filepath, Size: int64(free), //nolint:gosec
+				Err: fmt.Errorf("pruning backup files: %w", err)})
+		}
+	}
+
+	switch {
+	case low && !l.lowSpace:
+		l.event(&Event{Type: EventLowSpace, File: l.config.Filepath, Size: int64(free), //nolint:gosec
+			Err: fmt.Errorf("%w: %d bytes free, %d required", ErrLowSpace, free, l.config.MinFreeBytes)})
+	case !low && l.lowSpace:
+		l.event(&Event{Type: EventLowSpace, File: l.config.Filepath, Size: int64(free)}) //nolint:gosec
+	}
+
+	l.lowSpace = low
+}
+
+// freeSpace returns the lowest free space on the log directories, and true if it's below MinFreeBytes.
+// Directories that cannot be checked are ignored.
+func (l *Logger) freeSpace() (uint64, bool) {
+	var (
+		free    uint64
+		checked bool
+	)
+
+	for _, dir := range l.dirs {
+		space, err := l.Statfs(dir)
+		if err != nil {
+			continue
+		}
+
+		if !checked || space.Free < free {
+			free = space.Free
+			checked = true
+		}
+	}
+
+	return free, checked && free < l.config.MinFreeBytes
+}
+
+// writeLowSpace handles a write while free space is low, for the policies that do not write the log file.
+func (l *Logger) writeLowSpace(bytes []byte) (int, error) {
+	if l.config.LowSpace == LowSpaceStderr {
+		size, err := os.Stderr.Write(bytes)
+		if err != nil {
+			return size, fmt.Errorf("writing to stderr: %w", err)
+		}
+
+		return size, nil
+	}
+
+	return 0, ErrLowSpace
+}
+
+// truncate empties the active file instead of rotating it. Used by LowSpaceActiveOnly.
+func (l *Logger) truncate() (int64, error) {
+	size := l.size
+	l.scheduled = false
+	l.deferred = 0
+
+	if err := l.flush(); err != nil {
+		return size, err
+	}
+
+	if l.File != nil {
+		if err := l.File.Truncate(0); err != nil {
+			return size, fmt.Errorf("truncating log file %s: %w", l.config.Filepath, err)
+		}
+	}
+
+	l.size = 0
+	l.unsynced = 0
+	l.created = time.Now()
+
+	return size, nil
+}
@@ -0,0 +1,74 @@
+package rotatorr_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/introtator"
+)
+
+func TestMinFreeBytes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir    = t.TempDir()
+		events []*rotatorr.Event
+	)
+
+	for _, name := range []string{"app.1.log", "app.2.log"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("backup"), rotatorr.FileMode))
+	}
+
+	// No disk has this much space, so the guard is always on.
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:     filepath.Join(dir, "app.log"),
+		MinFreeBytes: math.MaxUint64,
+		LowSpace:     rotatorr.LowSpaceActiveOnly,
+		OnEvent:      func(event *rotatorr.Event) { events = append(events, event) },
+		Rotatorr:     &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(rotatorr.EventDelete, events[0].Type, "backups must be pruned")
+	assert.Equal(rotatorr.EventDelete, events[1].Type, "backups must be pruned")
+	assert.Equal(rotatorr.EventLowSpace, events[2].Type)
+	require.ErrorIs(t, events[2].Err, rotatorr.ErrLowSpace)
+	assert.NoFileExists(filepath.Join(dir, "app.1.log"))
+	assert.NoFileExists(filepath.Join(dir, "app.2.log"))
+
+	_, err = logger.Write([]byte("hello\n"))
+	require.NoError(t, err, "active-only must keep writing the active file")
+
+	size, err := logger.Rotate()
+	require.NoError(t, err)
+	assert.EqualValues(6, size)
+	assert.NoFileExists(filepath.Join(dir, "app.1.log"), "active-only must not create backups")
+
+	_, err = logger.Write([]byte("world\n"))
+	require.NoError(t, err)
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal("world\n", string(data), "rotation must truncate the active file")
+
+	// Drop is the default.
+	logger, err = rotatorr.New(&rotatorr.Config{
+		Filepath:     filepath.Join(dir, "drop.log"),
+		MinFreeBytes: math.MaxUint64,
+		Rotatorr:     &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	_, err = logger.Write([]byte("hello\n"))
+	require.ErrorIs(t, err, rotatorr.ErrLowSpace)
+	require.NoError(t, logger.Close())
+	assert.Zero(logger.Stats().Bytes)
+}
@@ -10,10 +10,23 @@
 //
 // Use this package if you write your own log file, and you're tired of your
 // log file growing indefinitely.
-// The included `introtatorr` and `timerotator`
+// The included `introtatorr`, `timerotator` and `daterotator`
 // modules allow a variety of naming conventions for backup files. They also
 // include options to delete old files based on age, count, or both.
 //
 //	https://pkg.go.dev/golift.io/rotatorr/introtator
 //	https://pkg.go.dev/golift.io/rotatorr/timerotator
+//	https://pkg.go.dev/golift.io/rotatorr/daterotator
+//
+// A log/slog Handler that writes to a rotating Logger is also included.
+//
+//	https://pkg.go.dev/golift.io/rotatorr/sloghandler
+//
+// Logger stats may be published with expvar or Prometheus using the metrics package.
+//
+//	https://pkg.go.dev/golift.io/rotatorr/metrics
+//
+// The admin package provides an HTTP handler to inspect and rotate logs.
+//
+//	https://pkg.go.dev/golift.io/rotatorr/admin
 package rotatorr
@@ -0,0 +1,57 @@
+package rotatorr
+
+import "time"
+
+// EventType identifies what happened in an Event.
+type EventType uint8
+
+// These are the events sent to Config.OnEvent.
+const (
+	EventPreRotate  EventType = iota + 1 // The active file is about to be rotated.
+	EventPostRotate                      // The active file was rotated. Err is set if it failed.
+	EventOpenError                       // The active file could not be opened.
+	EventWriteError                      // Writing or syncing the active file failed.
+	EventDelete                          // A backup file was deleted by retention. Err is set if it failed.
+	EventLowSpace                        // Free space is below MinFreeBytes. Err is nil when it recovers.
+)
+
+// Event is sent to Config.OnEvent. Not every member is set for every event type.
+type Event struct {
+	Type    EventType
+	Time    time.Time // When the event happened.
+	File    string    // The active log file, or the deleted backup file.
+	NewFile string    // The backup file name from the Rotatorr, for post-rotate events.
+	Size    int64     // Size of the rotated file, bytes written before an error, or free disk space.
+	Reason  Reason    // Why the file was rotated, for rotate events.
+	Err     error     // Set if something failed.
+}
+
+// String turns an EventType into words.
+func (e EventType) String() string {
+	switch e {
+	case EventPreRotate:
+		return "pre-rotate"
+	case EventPostRotate:
+		return "post-rotate"
+	case EventOpenError:
+		return "open error"
+	case EventWriteError:
+		return "write error"
+	case EventDelete:
+		return "delete"
+	case EventLowSpace:
+		return "low space"
+	default:
+		return "unknown"
+	}
+}
+
+// event counts an event in the stats, and sends it to the callback if there is one.
+func (l *Logger) event(event *Event) {
+	event.Time = time.Now()
+	l.stats.count(event)
+
+	if l.config.OnEvent != nil {
+		l.config.OnEvent(event)
+	}
+}
@@ -20,6 +20,7 @@ type Filer interface {
 	MkdirAll(path string, perm os.FileMode) error
 	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
 	Stat(filename string) (*FileInfo, error)
+	Statfs(path string) (*DiskSpace, error)
 }
 
 // Default returns a Filer interface that works, using default procedures.
@@ -35,6 +36,13 @@ type FileInfo struct {
 	CreateTime time.Time
 }
 
+// DiskSpace contains the size of a file system, and the space available to this user.
+// Created by Statfs().
+type DiskSpace struct {
+	Total uint64
+	Free  uint64
+}
+
 // File can be embedded in a custom type to provide the missing methods for the Filer interface.
 type File struct{}
 
@@ -67,3 +75,8 @@ func (f *File) OpenFile(name string, flag int, perm os.FileMode) (*os.File, erro
 func (f *File) Stat(filename string) (*FileInfo, error) {
 	return Stat(filename)
 }
+
+// Statfs provides the total and available space on the file system holding path.
+func (f *File) Statfs(path string) (*DiskSpace, error) {
+	return Statfs(path)
+}
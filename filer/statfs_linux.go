@@ -0,0 +1,21 @@
+package filer
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// Statfs returns the total and available space on the file system holding path.
+func Statfs(path string) (*DiskSpace, error) {
+	var stat syscall.Statfs_t
+
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, fmt.Errorf("statfs err: %w", err)
+	}
+
+	//nolint:gosec,unconvert // the field types differ by platform.
+	return &DiskSpace{
+		Total: uint64(stat.Blocks) * uint64(stat.Bsize),
+		Free:  uint64(stat.Bavail) * uint64(stat.Bsize),
+	}, nil
+}
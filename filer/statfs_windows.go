@@ -0,0 +1,32 @@
+package filer
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+//nolint:gochecknoglobals
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// Statfs returns the total and available space on the volume holding path.
+func Statfs(path string) (*DiskSpace, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("statfs err: %w", err)
+	}
+
+	var space DiskSpace
+
+	ret, _, err := getDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&space.Free)),
+		uintptr(unsafe.Pointer(&space.Total)),
+		0,
+	)
+	if ret == 0 {
+		return nil, fmt.Errorf("statfs err: %w", err)
+	}
+
+	return &space, nil
+}
@@ -1,5 +1,7 @@
 package rotatorr
 
+import "context"
+
 //go:generate mockgen -destination=mocks/rotatorr.go -package=mocks golift.io/rotatorr Rotatorr
 
 // Rotatorr allows passing in your own logic for file rotation.
@@ -17,3 +19,31 @@ type Rotatorr interface {
 	// This should do any validation and return a list of directories to create.
 	Dirs(fileName string) (dirPaths []string, err error)
 }
+
+// Waiter is an optional interface for a Rotatorr. If the Post method starts
+// background work (like compression), Wait should block until it's finished,
+// or the context is done. Logger.Shutdown calls this after closing the log file.
+type Waiter interface {
+	Wait(ctx context.Context) error
+}
+
+// DeleteNotifier is an optional interface for a Rotatorr. The Logger provides a hook
+// that should be called every time a backup file is deleted (or fails to delete) by
+// retention. The deletions are reported as events. Both included layouts satisfy this.
+type DeleteNotifier interface {
+	NotifyDelete(hook func(fileName string, err error))
+}
+
+// Lister is an optional interface for a Rotatorr. Backups returns the rotated backup
+// files for a log file, oldest first. Logger.Backups uses this, and both included
+// layouts satisfy it.
+type Lister interface {
+	Backups(fileName string) ([]string, error)
+}
+
+// Pruner is an optional interface for a Rotatorr. Prune deletes backup files for a log
+// file, oldest first, until enough returns true or no backups remain. The Logger uses
+// this to free disk space when Config.MinFreeBytes is set. Both included layouts satisfy it.
+type Pruner interface {
+	Prune(fileName string, enough func() bool) error
+}
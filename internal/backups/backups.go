@@ -0,0 +1,187 @@
+// Package backups holds the logic shared by the included layouts.
+// Each Layout builds a Config from its own fields when it needs one.
+package backups
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/filer"
+	"golift.io/rotatorr/retention"
+)
+
+// Config is the part of a Layout every layout has in common.
+type Config struct {
+	filer.Filer
+
+	FileCount  int
+	FileAge    time.Duration
+	TotalSize  int64
+	Retention  rotatorr.Retention
+	Recover    bool
+	Background rotatorr.Waiter
+	PostRotate func(fileName, newFile string)
+	Deleted    func(fileName string, err error) // set by NotifyDelete.
+}
+
+// Policy returns the retention policy built from FileCount, FileAge, TotalSize and Retention.
+func (c *Config) Policy() rotatorr.Retention {
+	policy := retention.AnyOf{
+		retention.Age(c.FileAge),
+		retention.Count(c.FileCount),
+		retention.Size(c.TotalSize),
+	}
+
+	if c.Retention != nil {
+		policy = append(policy, c.Retention)
+	}
+
+	return policy
+}
+
+// Backups turns file paths into backups for the retention policy. The times come from the
+// file names; when times is nil, modification times are used instead. Files are only stat'd
+// for their size (and modification time) when TotalSize or Retention is set.
+func (c *Config) Backups(files []string, times []time.Time) []*rotatorr.Backup {
+	backups := make([]*rotatorr.Backup, len(files))
+
+	for idx, fileName := range files {
+		backups[idx] = &rotatorr.Backup{Path: fileName}
+
+		if times != nil {
+			backups[idx].Time = times[idx]
+		}
+
+		if c.TotalSize < 1 && c.Retention == nil {
+			continue
+		}
+
+		if info, err := c.Stat(fileName); err == nil {
+			backups[idx].Size = info.Size()
+
+			if times == nil {
+				backups[idx].Time = info.ModTime()
+			}
+		}
+	}
+
+	return backups
+}
+
+// Delete deletes the backups a retention policy returned.
+func (c *Config) Delete(expired []*rotatorr.Backup) error {
+	for _, backup := range expired {
+		if err := c.Remove(backup.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Remove deletes an old backup file and reports it to the delete hook.
+func (c *Config) Remove(fileName string) error {
+	err := c.Filer.Remove(fileName)
+	if c.Deleted != nil {
+		c.Deleted(fileName, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error removing file: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes backup files, oldest first, until enough returns true.
+func (c *Config) Prune(files []string, enough func() bool) error {
+	for _, fileName := range files {
+		if enough() {
+			return nil
+		}
+
+		if err := c.Remove(fileName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start runs from a Layout's Dirs method. When PostRotate or Recover is set, orphaned compression
+// temp files for the Layout's own backups, those isBackup accepts, are removed from the archive
+// directory. With Recover, backups without a compression suffix are passed to PostRotate, to
+// finish compressions cut short when the app exited. Errors are ignored; everything is tried
+// again at the next startup.
+func (c *Config) Start(archiveDir, fileName string, lister rotatorr.Lister, isBackup func(name string) bool) {
+	if c.PostRotate == nil && !c.Recover {
+		return // nothing was compressed by this Layout.
+	}
+
+	_, _ = compressor.SweepFunc(c.Filer, archiveDir, isBackup)
+
+	if !c.Recover || c.PostRotate == nil {
+		return
+	}
+
+	files, _ := lister.Backups(fileName)
+	for _, backup := range files {
+		if backup != fileName && compressor.Suffix(backup) == "" {
+			c.PostRotate(fileName, backup)
+		}
+	}
+}
+
+// Wait waits for the Background work started by PostRotate.
+func (c *Config) Wait(ctx context.Context) error {
+	if c.Background == nil {
+		return nil
+	}
+
+	return c.Background.Wait(ctx) //nolint:wrapcheck
+}
+
+// Post calls PostRotate if it's set.
+func (c *Config) Post(fileName, newFile string) {
+	if c.PostRotate != nil {
+		c.PostRotate(fileName, newFile)
+	}
+}
+
+// Dirs returns the directory of the log file, and the archive directory if it's different.
+func Dirs(fileName, archiveDir string) []string {
+	if fpath := filepath.Dir(fileName); archiveDir != "" && fpath != archiveDir {
+		return []string{fpath, archiveDir}
+	}
+
+	return []string{filepath.Dir(fileName)}
+}
+
+// ArchiveDir returns the archive directory if one is set, otherwise the directory the log file is in.
+func ArchiveDir(fileName, archiveDir string) string {
+	if archiveDir != "" {
+		return archiveDir
+	}
+
+	return filepath.Dir(fileName)
+}
+
+// Prefix returns the prefix of a log file's backups: the log file name without
+// its path or extension, followed by the joiner.
+func Prefix(fileName, joiner string) string {
+	return strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)) + joiner
+}
+
+// Ext returns the extension for backup files: extension, or the log file's extension.
+func Ext(fileName, extension string) string {
+	if extension != "" {
+		return extension
+	}
+
+	return filepath.Ext(fileName)
+}
@@ -0,0 +1,138 @@
+package backups_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr/filer"
+	"golift.io/rotatorr/internal/backups"
+)
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		fileName = filepath.Join(t.TempDir(), "a.1.log")
+		deleted  []error
+		config   = &backups.Config{
+			Filer:   filer.Default(),
+			Deleted: func(_ string, err error) { deleted = append(deleted, err) },
+		}
+	)
+
+	require.NoError(t, os.WriteFile(fileName, []byte("12345"), 0o600))
+	require.NoError(t, config.Remove(fileName))
+	require.Error(t, config.Remove(fileName), "removing a missing file must fail")
+	assert.Len(deleted, 2, "failed deletes must be reported too")
+	assert.NoError(deleted[0])
+	assert.Error(deleted[1])
+}
+
+func TestRetention(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir     = t.TempDir()
+		files   = []string{filepath.Join(dir, "a.1.log"), filepath.Join(dir, "a.2.log"), filepath.Join(dir, "a.3.log")}
+		deleted []string
+		config  = &backups.Config{
+			Filer:     filer.Default(),
+			TotalSize: 10,
+			Deleted:   func(fileName string, _ error) { deleted = append(deleted, fileName) },
+		}
+	)
+
+	for _, fileName := range files {
+		require.NoError(t, os.WriteFile(fileName, []byte("12345"), 0o600))
+	}
+
+	named := []time.Time{time.Unix(1, 0), time.Unix(2, 0), time.Unix(3, 0)}
+	list := config.Backups(files, named)
+	assert.Equal(named[0], list[0].Time, "times from file names must be used")
+	assert.EqualValues(5, list[0].Size, "files must be stat'd when TotalSize is set")
+	assert.False(config.Backups(files, nil)[0].Time.IsZero(), "modification times must be used")
+
+	require.NoError(t, config.Delete(config.Policy().Expired(list)))
+	assert.Equal(files[:1], deleted, "the oldest backup over TotalSize must be deleted")
+
+	config.TotalSize = 0
+	assert.EqualValues(0, config.Backups(files, nil)[1].Size, "files must not be stat'd without TotalSize or Retention")
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir     = t.TempDir()
+		files   = []string{filepath.Join(dir, "a.1.log"), filepath.Join(dir, "a.2.log"), filepath.Join(dir, "a.3.log")}
+		deleted []string
+		count   int
+		config  = &backups.Config{
+			Filer:   filer.Default(),
+			Deleted: func(fileName string, _ error) { deleted = append(deleted, fileName) },
+		}
+	)
+
+	for _, fileName := range files {
+		require.NoError(t, os.WriteFile(fileName, []byte("12345"), 0o600))
+	}
+
+	require.NoError(t, config.Prune(files, func() bool { count++; return count > 2 }))
+	assert.Equal(files[:2], deleted, "pruning must stop when enough returns true")
+	require.Error(t, config.Prune(files, func() bool { return false }), "a failed delete must stop pruning")
+	assert.Len(deleted, 3, "pruning must stop at the first failed delete")
+}
+
+type waiter struct{ err error }
+
+func (w waiter) Wait(context.Context) error { return w.err }
+
+func TestPost(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		posted  []string
+		config  = &backups.Config{}
+		errTest = errors.New("still compressing")
+	)
+
+	config.Post("a.log", "a.1.log") // PostRotate is optional.
+	require.NoError(t, config.Wait(t.Context()), "Wait must not fail without Background")
+
+	config.PostRotate = func(_, newFile string) { posted = append(posted, newFile) }
+	config.Background = waiter{err: errTest}
+
+	config.Post("a.log", "a.1.log")
+	assert.Equal([]string{"a.1.log"}, posted)
+	assert.ErrorIs(config.Wait(t.Context()), errTest, "Wait must return the Background error")
+}
+
+func TestPaths(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir      = t.TempDir()
+		other    = filepath.Join(dir, "other")
+		fileName = filepath.Join(dir, "web.log")
+	)
+
+	assert.Equal([]string{dir}, backups.Dirs(fileName, dir))
+	assert.Equal([]string{dir}, backups.Dirs(fileName, ""))
+	assert.Equal([]string{dir, other}, backups.Dirs(fileName, other))
+	assert.Equal(other, backups.ArchiveDir(fileName, other))
+	assert.Equal(dir, backups.ArchiveDir(fileName, ""))
+	assert.Equal("web.", backups.Prefix(fileName, "."))
+	assert.Equal(".jsonl", backups.Ext(fileName, ".jsonl"))
+	assert.Equal(".log", backups.Ext(fileName, ""))
+}
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
-	"strings"
+
+	"golift.io/rotatorr/compressor"
 )
 
 // rotateAscending handles the rotation of integer log files. Integers just means
@@ -15,16 +16,14 @@ func (l *Layout) rotateAscending(logFiles *backupFiles, fileName string) (string
 	var (
 		dir     = l.getArchiveDir(fileName)
 		prefix  = l.getPrefix(fileName)
-		newPath = filepath.Join(dir, prefix+LogExt1)
+		logExt  = l.getExt(fileName)
+		newPath = filepath.Join(dir, prefix+"1"+logExt)
 	)
 
 	if len(logFiles.Files) != 0 {
 		// ascending and we have files. They all need to be renamed.
 		for idx, filePath := range logFiles.Files {
-			ext := LogExt
-			if strings.HasSuffix(logFiles.Files[idx], GZext) {
-				ext += GZext
-			}
+			ext := logExt + compressor.Suffix(logFiles.Files[idx])
 
 			if idx != len(logFiles.Files)-1 && logFiles.value[idx+1] != logFiles.value[idx]-1 {
 				continue // There's a gap in the list, so skip renaming one.
@@ -52,27 +51,10 @@ func (l *Layout) rotateAscending(logFiles *backupFiles, fileName string) (string
 	return newPath, nil
 }
 
-// deleteOldLogsAsc deletes old files based on max file count.
+// deleteOldLogsAsc deletes old files based on the retention policy.
+// Backup times are modification times.
 func (l *Layout) deleteOldLogsAsc(logFiles *backupFiles) error {
-	if l.FileCount < 1 {
-		return nil
-	}
-
-	count := len(logFiles.Files)
-
-	for _, f := range logFiles.Files {
-		if count <= l.FileCount {
-			break
-		}
-
-		err := l.Remove(f)
-		if err != nil {
-			return fmt.Errorf("error removing file: %w", err)
-		}
-
-		count--
-	}
+	config := l.config()
 
-	// fmt.Println("deleted:", files)
-	return nil
+	return config.Delete(config.Policy().Expired(config.Backups(logFiles.Files, nil))) //nolint:wrapcheck
 }
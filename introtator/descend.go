@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
-	"strings"
+
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/retention"
 )
 
 // rotate handles the rotation of integer log files. Integers just means
@@ -15,13 +18,11 @@ func (l *Layout) rotateDescending(logFiles *backupFiles, fileName string) (strin
 	var (
 		dir    = l.getArchiveDir(fileName)
 		prefix = l.getPrefix(fileName)
+		logExt = l.getExt(fileName)
 	)
 
 	for idx, filePath := range logFiles.Files {
-		ext := LogExt
-		if strings.HasSuffix(logFiles.Files[idx], GZext) {
-			ext += GZext
-		}
+		ext := logExt + compressor.Suffix(logFiles.Files[idx])
 
 		logFiles.value[idx] = idx + 1
 		logFiles.Files[idx] = filepath.Join(dir, prefix+strconv.Itoa(logFiles.value[idx])+ext)
@@ -37,7 +38,7 @@ func (l *Layout) rotateDescending(logFiles *backupFiles, fileName string) (strin
 		}
 	}
 
-	newPath := filepath.Join(dir, prefix+strconv.Itoa(len(logFiles.value)+1)+LogExt)
+	newPath := filepath.Join(dir, prefix+strconv.Itoa(len(logFiles.value)+1)+logExt)
 
 	err := l.Rename(fileName, newPath)
 	if err != nil {
@@ -50,32 +51,65 @@ func (l *Layout) rotateDescending(logFiles *backupFiles, fileName string) (strin
 	return newPath, nil
 }
 
-// deleteOldLogsDesc deletes old files based on max file count.
-func (l *Layout) deleteOldLogsDesc(logFiles *backupFiles) (*backupFiles, error) {
-	files := &backupFiles{Files: []string{}, value: []int{}}
-	count := len(logFiles.Files)
+// deleteOldLogsDesc deletes old files based on the retention policy. This runs before the
+// active file is rotated, so the numbers of the remaining files stay contiguous.
+func (l *Layout) deleteOldLogsDesc(logFiles *backupFiles, fileName string) (*backupFiles, error) {
+	var (
+		files   = &backupFiles{Files: []string{}, value: []int{}}
+		expired = make(map[string]bool)
+		config  = l.config()
+	)
 
-	if l.FileCount < 1 {
-		return files, nil
+	for _, backup := range l.policyDesc(fileName).Expired(config.Backups(logFiles.Files, nil)) {
+		expired[backup.Path] = true
 	}
 
 	for idx, filePath := range logFiles.Files {
-		if count < l.FileCount {
+		if !expired[filePath] {
 			files.Files = append(files.Files, filePath)
 			files.value = append(files.value, logFiles.value[idx])
 
 			continue
 		}
 
-		// fmt.Println("deleted", filePath, count)
-		err := l.Remove(filePath)
-		if err != nil {
-			return files, fmt.Errorf("error removing file: %w", err)
+		// fmt.Println("deleted", filePath)
+		if err := config.Remove(filePath); err != nil {
+			return files, err //nolint:wrapcheck
 		}
-
-		count--
 	}
 
 	// fmt.Println("kept:", files)
 	return files, nil
 }
+
+// policyDesc returns the retention policy for Descending order. It only gets the existing
+// backups, so FileCount and TotalSize are reduced to make room for the active file, which
+// becomes a backup after the policy runs.
+func (l *Layout) policyDesc(fileName string) rotatorr.Retention {
+	policy := retention.AnyOf{}
+
+	switch {
+	case l.FileCount == 1:
+		policy = append(policy, rotatorr.RetentionFunc(func(backups []*rotatorr.Backup) []*rotatorr.Backup {
+			return backups // the active file is the only backup kept.
+		}))
+	case l.FileCount > 1:
+		policy = append(policy, retention.Count(l.FileCount-1))
+	}
+
+	if l.TotalSize > 0 {
+		var size int64
+		if info, err := l.Stat(fileName); err == nil {
+			size = info.Size()
+		}
+
+		// Size(0) keeps everything, so keep 1 byte when the active file uses the whole budget.
+		policy = append(policy, retention.Size(max(l.TotalSize-size, 1)))
+	}
+
+	if l.Retention != nil {
+		policy = append(policy, l.Retention)
+	}
+
+	return policy
+}
@@ -11,13 +11,17 @@
 package introtator
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
 	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
 	"golift.io/rotatorr/filer"
+	"golift.io/rotatorr/internal/backups"
 )
 
 // Order defines which direction the files are written in.
@@ -34,31 +38,60 @@ const (
 // This also sets how many files are kept; default is unlimited. Recommend setting
 // FileCount when FileOrder is set to Ascending (default), otherwise the app may
 // spend a lot of time renaming files. If you enable compression with a PostRotate
-// hook, make sure compression finishes before the files are rotated.
+// hook, make sure compression finishes before the files are rotated: compress in the
+// foreground, or set the background compressor (like a compressor.Pool) as Background.
 type Layout struct {
 	filer.Filer
 
 	ArchiveDir string // Location where rotated backup logs are moved to.
 	FileCount  int    // Maximum number of rotated log files.
-	FileOrder  Order  // Control the order of the integer-named backup log files.
+	// TotalSize is the maximum total size of rotated log files (compressed or not), in bytes.
+	// In Descending order, room is made for the active file before it's rotated, so the
+	// oldest files are deleted first and the remaining numbers stay contiguous.
+	TotalSize int64
+	FileOrder Order  // Control the order of the integer-named backup log files.
+	Extension string // Backup file extension, like ".jsonl". Default: the log file's extension.
+	// Retention is a custom policy applied with FileCount and TotalSize. Any of them may delete a
+	// file. Backup times are modification times. Setting this stats every backup on rotation.
+	// The policy only gets existing backups; in Descending order it runs before rotating.
+	Retention rotatorr.Retention
+	// Recover passes backups that lack a compression suffix to PostRotate when Dirs is called at
+	// startup. This finishes compressions that were queued or running when the app exited.
+	// Only set this when PostRotate compresses files.
+	Recover bool
+	// Background is the background work started by PostRotate, like a compressor.Group or compressor.Pool.
+	// Rotate waits for it before renaming any backups, and Logger.Shutdown waits for it. Rotate
+	// holds the Logger's lock while it waits, so do not write compression reports to the same Logger.
+	Background rotatorr.Waiter
 	PostRotate func(fileName, newFile string)
+	deleted    func(fileName string, err error) // set by NotifyDelete.
 }
 
-// Some constant this package uses.
+// Joiner joins the prefix with the integer.
+const Joiner = "."
+
+// These were the only backup file extensions before Layout.Extension and compressor codecs.
+//
+// Deprecated: The extension comes from Layout.Extension or the log file name, and
+// every suffix registered with the compressor package is recognized.
 const (
-	LogExt  = ".log"  // suffixed to an integer.
-	LogExt1 = "1.log" // suffixed to the prefix.
-	GZext   = ".gz"   // trimmed off found files.
-	Joiner  = "."     // joins prefix with integer.
+	LogExt  = ".log"
+	LogExt1 = "1.log"
+	GZext   = ".gz"
 )
 
 // Rotate forces the log to rotate immediately. Returns the new name of the rotated log.
+// Background work is waited for first, so no backup is renamed while it compresses.
 func (l *Layout) Rotate(fileName string) (string, error) {
+	if err := l.Wait(context.Background()); err != nil {
+		return "", fmt.Errorf("waiting for background work: %w", err)
+	}
+
 	switch logFiles := l.getAllLogFiles(fileName); l.FileOrder {
 	case Descending:
 		sort.Sort(logFiles)
 
-		remainingfiles, err := l.deleteOldLogsDesc(logFiles)
+		remainingfiles, err := l.deleteOldLogsDesc(logFiles, fileName)
 		if err != nil {
 			return "", err
 		}
@@ -88,35 +121,82 @@ func (l *Layout) Dirs(fileName string) ([]string, error) {
 		l.FileOrder = Ascending
 	}
 
-	switch fpath := filepath.Dir(fileName); {
-	case l.ArchiveDir == "" || fpath == l.ArchiveDir:
-		return []string{fpath}, nil
-	default:
-		return []string{fpath, l.ArchiveDir}, nil
-	}
+	l.config().Start(l.getArchiveDir(fileName), fileName, l, l.isBackup(fileName))
+
+	return backups.Dirs(fileName, l.ArchiveDir), nil
 }
 
 // Post satisfies the Rotatorr interface.
 func (l *Layout) Post(fileName, newFile string) {
-	if l.PostRotate != nil {
-		l.PostRotate(fileName, newFile)
+	l.config().Post(fileName, newFile)
+}
+
+// NotifyDelete satisfies the rotatorr.DeleteNotifier interface.
+// The hook is called after every attempt to delete an old backup file.
+func (l *Layout) NotifyDelete(hook func(fileName string, err error)) {
+	l.deleted = hook
+}
+
+// config returns the settings shared with the other layouts.
+func (l *Layout) config() *backups.Config {
+	return &backups.Config{
+		Filer:      l.Filer,
+		FileCount:  l.FileCount,
+		TotalSize:  l.TotalSize,
+		Retention:  l.Retention,
+		Recover:    l.Recover,
+		Background: l.Background,
+		PostRotate: l.PostRotate,
+		Deleted:    l.deleted,
 	}
 }
 
-// GetPrefix returns a file's prefix. Removes the path and extension.
+// Backups satisfies the rotatorr.Lister interface.
+// Returns the backup files for a log file, oldest first.
+func (l *Layout) Backups(fileName string) ([]string, error) {
+	logFiles := l.getAllLogFiles(fileName)
+
+	if l.FileOrder == Descending {
+		sort.Sort(logFiles) // the lowest integer is the oldest.
+	} else {
+		sort.Sort(sort.Reverse(logFiles))
+	}
+
+	return logFiles.Files, nil
+}
+
+// Prune satisfies the rotatorr.Pruner interface.
+// Deletes backup files, oldest first, until enough returns true.
+func (l *Layout) Prune(fileName string, enough func() bool) error {
+	files, err := l.Backups(fileName)
+	if err != nil {
+		return err
+	}
+
+	return l.config().Prune(files, enough) //nolint:wrapcheck
+}
+
+// Wait satisfies the rotatorr.Waiter interface. This waits for the Background work
+// started by PostRotate, so Logger.Shutdown can wait for it to finish.
+func (l *Layout) Wait(ctx context.Context) error {
+	return l.config().Wait(ctx) //nolint:wrapcheck
+}
+
+// GetPrefix returns a file's prefix. Removes the path and the log file's extension.
 // This is used internally, but exposed for convenience when writing your own logic.
 func (l *Layout) getPrefix(fileName string) string {
-	return strings.TrimSuffix(filepath.Base(fileName), LogExt) + Joiner
+	return backups.Prefix(fileName, Joiner)
+}
+
+// getExt returns the extension for backup files: Extension, or the log file's extension.
+func (l *Layout) getExt(fileName string) string {
+	return backups.Ext(fileName, l.Extension)
 }
 
 // GetArchiveDir returns the archive directory if one is set,
 // otherwise the directory the log file is in.
 func (l *Layout) getArchiveDir(fileName string) string {
-	if l.ArchiveDir != "" {
-		return l.ArchiveDir
-	}
-
-	return filepath.Dir(fileName)
+	return backups.ArchiveDir(fileName, l.ArchiveDir)
 }
 
 // GetAllLogFiles finds all the backup log files that match our pattern.
@@ -125,6 +205,7 @@ func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 		dir    = l.getArchiveDir(fileName)
 		list   = &backupFiles{Files: []string{}, value: []int{}}
 		prefix = l.getPrefix(fileName)
+		ext    = l.getExt(fileName)
 	)
 
 	files, err := l.ReadDir(dir)
@@ -134,14 +215,7 @@ func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 
 	for _, file := range files {
 		name := file.Name()
-		if !strings.HasPrefix(name, prefix) {
-			continue // not our file.
-		}
-
-		part := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), GZext), LogExt)
-
-		i, err := strconv.Atoi(part)
-		if err == nil {
+		if i, ok := parse(name, prefix, ext); ok {
 			list.Files = append(list.Files, filepath.Join(dir, name))
 			list.value = append(list.value, i)
 		}
@@ -151,5 +225,34 @@ func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 	return list
 }
 
-// Our interface must satify a rotatorr.Rotatorr.
-var _ rotatorr.Rotatorr = (*Layout)(nil)
+// parse returns the integer in a backup file name, and false if the name is not one of our backups.
+func parse(name, prefix, ext string) (int, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false // not our file.
+	}
+
+	part := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressor.Suffix(name))
+
+	i, err := strconv.Atoi(strings.TrimSuffix(part, ext))
+
+	return i, err == nil
+}
+
+// isBackup returns a function that reports if a file name, without a path, is a backup of fileName.
+func (l *Layout) isBackup(fileName string) func(name string) bool {
+	prefix, ext := l.getPrefix(fileName), l.getExt(fileName)
+
+	return func(name string) bool {
+		_, ok := parse(name, prefix, ext)
+		return ok
+	}
+}
+
+// Our interface must satify a rotatorr.Rotatorr and the optional interfaces.
+var (
+	_ rotatorr.Rotatorr       = (*Layout)(nil)
+	_ rotatorr.Waiter         = (*Layout)(nil)
+	_ rotatorr.DeleteNotifier = (*Layout)(nil)
+	_ rotatorr.Lister         = (*Layout)(nil)
+	_ rotatorr.Pruner         = (*Layout)(nil)
+)
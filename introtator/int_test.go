@@ -2,12 +2,18 @@ package introtator_test
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
 	"golift.io/rotatorr/filer"
 	"golift.io/rotatorr/introtator"
 	"golift.io/rotatorr/mocks"
@@ -95,3 +101,227 @@ func TestRotateFirst(t *testing.T) {
 	assert.Empty(file, "the file must be empty when rotation fails.")
 	require.ErrorIs(t, err, errTest, "the rename error must be returned.")
 }
+
+func TestTotalSize(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	for _, order := range []introtator.Order{introtator.Ascending, introtator.Descending} {
+		dir := t.TempDir()
+		fileName := filepath.Join(dir, "service.log")
+		layout := &introtator.Layout{FileOrder: order, TotalSize: 25}
+
+		_, err := layout.Dirs(fileName)
+		require.NoError(t, err)
+
+		for range 5 {
+			require.NoError(t, os.WriteFile(fileName, []byte("0123456789"), 0o600))
+			_, err = layout.Rotate(fileName)
+			require.NoError(t, err)
+		}
+
+		backups, err := layout.Backups(fileName)
+		require.NoError(t, err)
+
+		if order == introtator.Ascending {
+			assert.Equal([]string{filepath.Join(dir, "service.2.log"), filepath.Join(dir, "service.1.log")}, backups,
+				"the oldest files must be deleted to stay under TotalSize")
+		} else {
+			// Backups are deleted before the active file is rotated, so the numbers have no gaps.
+			assert.Equal([]string{filepath.Join(dir, "service.1.log"), filepath.Join(dir, "service.2.log")}, backups,
+				"the oldest files must be deleted to stay under TotalSize, and the rest renumbered")
+		}
+
+		var total int64
+
+		for _, backup := range backups {
+			info, err := os.Stat(backup)
+			require.NoError(t, err)
+
+			total += info.Size()
+		}
+
+		assert.LessOrEqual(total, layout.TotalSize, "backups must not use more than TotalSize")
+	}
+}
+
+func TestExtension(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	tests := []struct {
+		layout  *introtator.Layout
+		file    string
+		backups []string
+	}{
+		{&introtator.Layout{}, "service.json", []string{"service.2.json", "service.1.json"}},
+		{&introtator.Layout{}, "service", []string{"service.2", "service.1"}},
+		{&introtator.Layout{Extension: ".txt"}, "app.txt", []string{"app.2.txt", "app.1.txt"}},
+		{&introtator.Layout{Extension: ".jsonl"}, "web.log", []string{"web.2.jsonl", "web.1.jsonl"}},
+	}
+
+	for _, test := range tests {
+		fileName := filepath.Join(dir, test.file)
+		_, err := test.layout.Dirs(fileName)
+		require.NoError(t, err)
+
+		for range 2 {
+			require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+			_, err = test.layout.Rotate(fileName)
+			require.NoError(t, err)
+		}
+
+		backups, err := test.layout.Backups(fileName)
+		require.NoError(t, err)
+
+		for idx := range test.backups {
+			test.backups[idx] = filepath.Join(dir, test.backups[idx])
+		}
+
+		assert.Equal(test.backups, backups, "the extension must be kept, and backups must be found")
+	}
+}
+
+func TestCompressedSuffixes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.log")
+	layout := &introtator.Layout{}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	for _, name := range []string{"service.1.log.zz", "service.2.log.deflate", "service.3.log.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+	_, err = layout.Rotate(fileName)
+	require.NoError(t, err)
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{
+		filepath.Join(dir, "service.4.log.gz"),
+		filepath.Join(dir, "service.3.log.deflate"),
+		filepath.Join(dir, "service.2.log.zz"),
+		filepath.Join(dir, "service.1.log"),
+	}, backups, "every registered codec suffix must be found and kept when renaming")
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir      = t.TempDir()
+		fileName = filepath.Join(dir, "service.log")
+		posted   []string
+		layout   = &introtator.Layout{
+			Recover:    true,
+			PostRotate: func(_, newFile string) { posted = append(posted, newFile) },
+		}
+	)
+
+	for _, name := range []string{"service.log", "service.1.log", "service.2.log.gz", ".service.3.log.gz.tmp"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o600))
+	}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{filepath.Join(dir, "service.1.log")}, posted,
+		"only the uncompressed backup must be passed to PostRotate")
+	assert.NoFileExists(filepath.Join(dir, ".service.3.log.gz.tmp"), "orphaned temp files must be swept")
+}
+
+func TestRetentionInput(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	for _, order := range []introtator.Order{introtator.Ascending, introtator.Descending} {
+		var (
+			dir      = t.TempDir()
+			fileName = filepath.Join(dir, "service.log")
+			seen     []string
+			layout   = &introtator.Layout{
+				FileOrder: order,
+				FileCount: 2,
+				Retention: rotatorr.RetentionFunc(func(backups []*rotatorr.Backup) []*rotatorr.Backup {
+					for _, backup := range backups {
+						seen = append(seen, backup.Path)
+					}
+
+					return nil
+				}),
+			}
+		)
+
+		_, err := layout.Dirs(fileName)
+		require.NoError(t, err)
+
+		for range 3 {
+			require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+			_, err = layout.Rotate(fileName)
+			require.NoError(t, err)
+		}
+
+		assert.NotContains(seen, fileName, "the policy must only get real backups")
+
+		backups, err := layout.Backups(fileName)
+		require.NoError(t, err)
+		assert.Len(backups, 2, "FileCount must still be kept")
+	}
+}
+
+// slowCodec is gzip, but slow enough that rotations happen while files compress.
+type slowCodec struct{ compressor.Codec }
+
+func (s slowCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	time.Sleep(20 * time.Millisecond)
+	return s.Codec.NewWriter(w, level) //nolint:wrapcheck
+}
+
+func TestBackground(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		fileName = filepath.Join(t.TempDir(), "service.log")
+		pool     = compressor.NewPool(&compressor.PoolConfig{Workers: 2, Codec: slowCodec{compressor.Gzip}})
+		layout   = &introtator.Layout{Background: pool, PostRotate: pool.PostRotate}
+	)
+
+	defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	for idx := range 5 {
+		require.NoError(t, os.WriteFile(fileName, []byte(fmt.Sprint(idx)), 0o600))
+
+		newFile, err := layout.Rotate(fileName)
+		require.NoError(t, err)
+		layout.Post(fileName, newFile)
+	}
+
+	require.NoError(t, layout.Wait(t.Context()))
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	require.Len(t, backups, 5, "no backup may be lost while compressing")
+
+	for idx, backup := range backups {
+		assert.Equal(compressor.SuffixGZ, compressor.Suffix(backup), "every backup must be compressed")
+
+		reader, err := compressor.Open(backup)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		assert.Equal(fmt.Sprint(idx), string(data), "backups must keep their order")
+	}
+}
@@ -0,0 +1,66 @@
+package rotatorr
+
+// LargeWrites decides what happens to a single write that is larger than Config.FileSize.
+type LargeWrites uint8
+
+// These are the available large-write policies.
+const (
+	RejectLarge LargeWrites = iota // Drop the write and return ErrWriteTooLarge. This is the default.
+	WriteLarge                     // Rotate, then write the whole message into a fresh file.
+	SplitLarge                     // Split the message across as many rotated files as needed.
+)
+
+// writeLarge rotates the active file (unless it's empty) and writes an oversized
+// message into the fresh file. The next write rotates that file again.
+func (l *Logger) writeLarge(bytes []byte) (int, error) {
+	err := l.checkAndRotate(0)
+	if err == nil && l.size > 0 {
+		_, err = l.rotate(ReasonSize)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return l.writeFile(bytes)
+}
+
+// writeSplit fills the active file with as much of an oversized message as fits,
+// rotates, and repeats until the whole message is written. With a Delimiter, each
+// file is cut after the last record that fits, if there is one.
+func (l *Logger) writeSplit(bytes []byte) (int, error) {
+	var written int
+
+	for len(bytes) > 0 {
+		err := l.checkAndRotate(0)
+		if err != nil {
+			return written, err
+		}
+
+		room := l.config.FileSize - l.size
+		if room <= 0 {
+			room = l.config.FileSize // this chunk rotates the file.
+		}
+
+		chunk := bytes[:min(int64(len(bytes)), room)]
+		// Prefer splitting on a record delimiter, and rotate right after it.
+		cut := l.lastRecordEnd(chunk)
+		if len(chunk) < len(bytes) && cut > 0 {
+			chunk = chunk[:cut]
+		}
+
+		size, err := l.write(chunk)
+		written += size
+		bytes = bytes[size:]
+
+		if err == nil && len(bytes) > 0 && size == cut {
+			_, err = l.rotate(ReasonSize)
+		}
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
@@ -1,6 +1,7 @@
 package rotatorr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"time"
 
 	"golift.io/rotatorr/filer"
@@ -23,6 +25,15 @@ const (
 // struct members are omitted.
 const DefaultMaxSize = 10 * 1024 * 1024
 
+// These defaults are used when BufferSize, a SyncPolicy or a Delimiter is set and the
+// matching FlushInterval, SyncInterval, SyncSize or MaxOverflow Config struct members are omitted.
+const (
+	DefaultFlushInterval = time.Second
+	DefaultSyncInterval  = time.Second
+	DefaultSyncSize      = 1024 * 1024
+	DefaultMaxOverflow   = 64 * 1024
+)
+
 // openRetryInterval is how long to wait before retrying openLog after a failure.
 // Prevents a storm of syscalls when the log file has permission or other persistent errors.
 const openRetryInterval = 10 * time.Second
@@ -31,6 +42,8 @@ const openRetryInterval = 10 * time.Second
 var (
 	ErrWriteTooLarge = errors.New("log msg length exceeds max file size")
 	ErrNilInterface  = errors.New("nil Rotatorr interface provided")
+	ErrClosed        = errors.New("logger is closed")
+	ErrLowSpace      = errors.New("free disk space is below the minimum")
 )
 
 // Config is the data needed to create a new Log Rotatorr.
@@ -41,6 +54,52 @@ type Config struct {
 	DirMode  os.FileMode   // POSIX mode for new folders.
 	Every    time.Duration // Maximum log file age. Rotate every hour or day, etc.
 	FileSize int64         // Maximum log file size in bytes. Default is unlimited (no rotation).
+	// Delimiter makes rotation wait until the active file ends with this record delimiter,
+	// like "\n", so a record written with several Write calls never straddles two files.
+	// SplitLarge also splits messages on this delimiter when possible.
+	Delimiter []byte
+	// MaxOverflow limits how many bytes may be written while a rotation waits for the
+	// Delimiter. The file is rotated mid-record after this. Default: 64 kilobytes.
+	MaxOverflow int64
+	// LargeWrites decides what happens to a single write larger than FileSize.
+	// The default, RejectLarge, drops the write and returns ErrWriteTooLarge.
+	LargeWrites LargeWrites
+	// OnEvent is called for rotations, open and write failures, and retention deletions.
+	// This blocks logging, so make it snappy, and do not write to this Logger from it.
+	OnEvent func(event *Event)
+	// Schedule rotates the log on wall-clock boundaries, like the top of every hour.
+	// This is driven by a timer, so rotation happens even when nothing is being written.
+	// Empty log files are not rotated. Use with or instead of Every and FileSize.
+	Schedule Schedule
+	// BufferSize enables an in-memory write buffer of this many bytes. Default is unbuffered.
+	// Buffered data is written to the file when the buffer fills, on every FlushInterval,
+	// on rotation, on Close and when Flush is called. Write errors may surface on a later call.
+	BufferSize int
+	// FlushInterval is how often buffered data is written to the file. Default: 1 second.
+	FlushInterval time.Duration
+	// SyncPolicy controls when the active file is fsync'd. Default is SyncNever.
+	// Every policy except SyncNever also syncs before rotating and closing the file,
+	// and syncs the log directories after the Rotatorr renames files.
+	SyncPolicy   SyncPolicy
+	SyncSize     int64         // Bytes written between syncs with SyncEveryBytes. Default: 1MB.
+	SyncInterval time.Duration // Time between syncs with SyncEveryInterval. Default: 1 second.
+	// CopyTruncate rotates by copying the active file to the name the Rotatorr picks, and
+	// then truncating the active file in place. Use this when other processes write to the
+	// same file with their own file descriptor. Writes made during the copy may be lost.
+	// If the app exits during the copy, the active file is left at .<name>.copytruncate
+	// next to Filepath; it is moved back (with Filepath appended) when the Logger starts.
+	// This does not work on Windows, because Windows does not allow moving an open file.
+	CopyTruncate bool
+	// ReopenCheck is how often to check if Filepath was moved or deleted by another process.
+	// When the path no longer points to the open file, it is reopened. Default is no checks.
+	ReopenCheck time.Duration
+	// MinFreeBytes is the free disk space required on the file systems holding the log
+	// file and its backups (every directory the Rotatorr returns from Dirs). Below this,
+	// the oldest backups are pruned if the Rotatorr is a Pruner; if space is still short,
+	// LowSpace decides what happens to writes. Default is no minimum.
+	MinFreeBytes uint64
+	LowSpace     LowSpace      // What to do with writes while space is low. Default: LowSpaceDrop.
+	SpaceCheck   time.Duration // How often free space is checked. Default: 10 seconds.
 }
 
 // Logger is what you get in return for providing a Config. Use this to set log output.
@@ -49,10 +108,21 @@ type Logger struct {
 	filer.Filer // overridable file system procedures.
 
 	config      *Config       // incoming configurtation.
-	log         chan []byte   // incoming log messages passed across go routines.
-	resp        chan *resp    // response sent back across go routines.
-	signal      chan struct{} // used for Rotate and Close ops.
-	size        int64         // the size of the active open file.
+	mu          sync.Mutex    // serializes writes, rotations and everything else touching the file.
+	buf         []byte        // buffered log messages not yet written to the file.
+	done        chan struct{} // closed to stop the go routine.
+	exited      chan struct{} // closed when the go routine returns.
+	closeOnce   sync.Once     // makes Close idempotent.
+	closed      bool          // set by Close; everything returns ErrClosed after this.
+	last        *Rotation     // the most recent rotation.
+	stats       Stats         // counters returned by Stats().
+	size        int64         // the size of the active open file, including buffered data.
+	unsynced    int64         // bytes written since the active file was last synced.
+	midRecord   bool          // the last write did not end with the record delimiter.
+	deferred    int64         // bytes written while a rotation waits for the end of a record.
+	scheduled   bool          // the Schedule timer asked for a rotation.
+	lowSpace    bool          // free disk space is below MinFreeBytes.
+	dirs        []string      // directories from the Rotatorr; checked for free space.
 	created     time.Time     // the date the active open file was created.
 	File        *os.File      // The active open file. Useful for direct writing.
 	Interface   Rotatorr      // copied from config for brevity.
@@ -60,12 +130,6 @@ type Logger struct {
 	lastOpened  time.Time     // when openLog was last attempted (for backoff).
 }
 
-// resp is used to send responses back across our go routines.
-type resp struct {
-	size int64
-	err  error
-}
-
 // New takes in your configuration and returns a Logger you can use with
 // log.SetOutput(). The provided logger handles log rotation and dispatching
 // post-actions like compression.
@@ -94,33 +158,84 @@ func NewMust(config *Config) *Logger {
 	return logger
 }
 
-// Write sends data directly to the file. This satisfies the io.ReadCloser interface.
+// Write sends data to the file, or to the buffer if Config.BufferSize is set.
+// This satisfies the io.Writer interface. Writes are serialized with a mutex,
+// so concurrent callers never wait on a go routine round trip.
 // You should generally not call this and instead pass *Logger into log.SetOutput().
 func (l *Logger) Write(b []byte) (int, error) {
-	l.log <- b
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return 0, ErrClosed
+	}
 
-	resp := <-l.resp
+	size, err := l.write(b)
+	l.stats.Writes++
+	l.stats.Bytes += uint64(size) //nolint:gosec // size is never negative.
 
-	return int(resp.size), resp.err
+	return size, err
+}
+
+// Flush writes any buffered data to the active log file.
+// This is a no-op when Config.BufferSize is not set.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	return l.flush()
 }
 
 // Rotate forces the log to rotate immediately. Returns the size of the rotated log.
 func (l *Logger) Rotate() (int64, error) {
-	l.signal <- struct{}{}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	resp := <-l.resp
+	if l.closed {
+		return 0, ErrClosed
+	}
 
-	return resp.size, resp.err
+	return l.rotate(ReasonManual)
 }
 
-// Close stops the go routines, closes the active log file session and all channels.
-// If another Write() is sent, a panic will ensue.
+// Close flushes the buffer, stops the go routine and closes the active log file.
+// Calling Close more than once is safe; only the first call returns an error.
+// Every other method returns ErrClosed after the Logger is closed.
 func (l *Logger) Close() error {
-	defer close(l.resp)
+	var err error
+
+	l.closeOnce.Do(func() {
+		close(l.done)
+		<-l.exited
 
-	close(l.signal)
+		l.mu.Lock()
+		defer l.mu.Unlock()
 
-	return (<-l.resp).err
+		l.closed = true
+		err = l.stop()
+	})
+
+	return err
+}
+
+// Shutdown closes the Logger, then waits for background work started by the
+// Rotatorr's Post method (like compression) to finish. The Rotatorr must satisfy
+// the Waiter interface for the latter; the included layouts wait for their Background.
+// Returns early with the context's error if it's done before the work finishes.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	err := l.Close()
+
+	if waiter, ok := l.Interface.(Waiter); ok {
+		if waitErr := waiter.Wait(ctx); waitErr != nil && err == nil {
+			err = fmt.Errorf("waiting for rotatorr: %w", waitErr)
+		}
+	}
+
+	return err
 }
 
 // initialize runs all the startup routines.
@@ -129,9 +244,8 @@ func (l *Logger) initialize(ignoreErrors bool) error {
 
 	defer func() {
 		if err == nil || ignoreErrors {
-			l.log = make(chan []byte)
-			l.resp = make(chan *resp)
-			l.signal = make(chan struct{})
+			l.done = make(chan struct{})
+			l.exited = make(chan struct{})
 
 			go l.processLogChannel()
 		}
@@ -146,6 +260,13 @@ func (l *Logger) initialize(ignoreErrors bool) error {
 		return err
 	}
 
+	err = l.recoverStash()
+	if err != nil {
+		return err
+	}
+
+	l.checkSpace()
+
 	return l.checkAndRotate(0)
 }
 
@@ -160,6 +281,30 @@ func (l *Logger) setConfigDefaults() error {
 		l.config.FileSize = DefaultMaxSize
 	}
 
+	if l.config.BufferSize > 0 {
+		l.buf = make([]byte, 0, l.config.BufferSize)
+
+		if l.config.FlushInterval <= 0 {
+			l.config.FlushInterval = DefaultFlushInterval
+		}
+	}
+
+	if len(l.config.Delimiter) > 0 && l.config.MaxOverflow <= 0 {
+		l.config.MaxOverflow = DefaultMaxOverflow
+	}
+
+	if l.config.SyncPolicy == SyncEveryBytes && l.config.SyncSize <= 0 {
+		l.config.SyncSize = DefaultSyncSize
+	}
+
+	if l.config.SyncPolicy == SyncEveryInterval && l.config.SyncInterval <= 0 {
+		l.config.SyncInterval = DefaultSyncInterval
+	}
+
+	if l.config.MinFreeBytes > 0 && l.config.SpaceCheck <= 0 {
+		l.config.SpaceCheck = DefaultSpaceCheck
+	}
+
 	if l.config.DirMode == 0 {
 		l.config.DirMode = DirMode
 	}
@@ -168,41 +313,83 @@ func (l *Logger) setConfigDefaults() error {
 		l.config.FileMode = FileMode
 	}
 
+	if notifier, ok := l.Interface.(DeleteNotifier); ok {
+		notifier.NotifyDelete(func(fileName string, err error) {
+			l.event(&Event{Type: EventDelete, File: fileName, Err: err})
+		})
+	}
+
 	dirs, err := l.Interface.Dirs(l.config.Filepath)
 	if err != nil {
 		return fmt.Errorf("validating Rotatorr: %w", err)
 	}
 
+	l.dirs = dirs
+
 	for _, dir := range dirs {
 		err := l.MkdirAll(dir, l.config.DirMode)
 		if err != nil {
-			return fmt.Errorf("making directories for logfiles: %w", err)
+			err = fmt.Errorf("making directories for logfiles: %w", err)
+			l.event(&Event{Type: EventOpenError, File: dir, Err: err})
+
+			return err
 		}
 	}
 
 	return nil
 }
 
-// processLogChannel runs in a go routine and reads the incoming logs channel.
-// Received logs are dispatched to the write method. Replies are then sent to the
-// response channel. This also handles log rotation and routine shutdown. Everything
-// except specific background actions (compression?) happen in this one go routine.
+// processLogChannel runs in a go routine until Close is called. This flushes, syncs and
+// checks the active file on intervals, and rotates the active file when the Schedule says so.
+// Everything else happens in the caller's go routine; the mutex keeps a single writer.
 func (l *Logger) processLogChannel() {
+	defer close(l.exited)
+
+	flush, stopFlush := newTicker(l.config.FlushInterval, l.config.BufferSize > 0)
+	defer stopFlush()
+
+	fsync, stopSync := newTicker(l.config.SyncInterval, l.config.SyncPolicy == SyncEveryInterval)
+	defer stopSync()
+
+	moved, stopMoved := newTicker(l.config.ReopenCheck, true)
+	defer stopMoved()
+
+	space, stopSpace := newTicker(l.config.SpaceCheck, l.config.MinFreeBytes > 0)
+	defer stopSpace()
+
+	schedule, resetSchedule := l.newScheduleTimer()
+	defer resetSchedule(false)
+
 	for {
 		select {
-		case b := <-l.log:
-			size, err := l.write(b)
-			l.resp <- &resp{int64(size), err}
-		case _, ok := <-l.signal:
-			if !ok {
-				l.signal = nil
-				l.resp <- &resp{err: l.stop()}
-
-				return
+		case <-flush:
+			l.mu.Lock()
+			_ = l.flush() // the next write or flush will find the same error.
+			l.mu.Unlock()
+		case <-fsync:
+			l.mu.Lock()
+			_ = l.sync() // the next write or sync will find the same error.
+			l.mu.Unlock()
+		case <-moved:
+			l.mu.Lock()
+			_ = l.checkMoved() // open errors are retried on the next write.
+			l.mu.Unlock()
+		case <-space:
+			l.mu.Lock()
+			l.checkSpace()
+			l.mu.Unlock()
+		case <-schedule:
+			l.mu.Lock()
+
+			if l.size > 0 {
+				l.scheduled = true
+				_ = l.checkAndRotate(0) // open errors are retried on the next write.
 			}
 
-			size, err := l.rotate()
-			l.resp <- &resp{size, err}
+			l.mu.Unlock()
+			resetSchedule(true)
+		case <-l.done:
+			return
 		}
 	}
 }
@@ -213,7 +400,10 @@ func (l *Logger) processLogChannel() {
 func (l *Logger) openLog() error {
 	err := l.MkdirAll(filepath.Dir(l.config.Filepath), l.config.DirMode)
 	if err != nil {
-		return fmt.Errorf("making directories for logfiles: %w", err)
+		err = fmt.Errorf("making directories for logfiles: %w", err)
+		l.event(&Event{Type: EventOpenError, File: l.config.Filepath, Err: err})
+
+		return err
 	}
 
 	perm := os.O_WRONLY | os.O_APPEND
@@ -221,7 +411,8 @@ func (l *Logger) openLog() error {
 	info, err := l.Stat(l.config.Filepath)
 	if err != nil {
 		// File doesn't exist, or something wrong, truncate it!
-		perm = os.O_WRONLY | os.O_TRUNC | os.O_CREATE
+		// Append mode is kept so writes follow truncations by copytruncate or other processes.
+		perm = os.O_WRONLY | os.O_APPEND | os.O_TRUNC | os.O_CREATE
 		l.size = 0
 		l.created = time.Now()
 	} else {
@@ -232,27 +423,74 @@ func (l *Logger) openLog() error {
 
 	l.File, err = l.OpenFile(l.config.Filepath, perm, l.config.FileMode)
 	if err != nil {
-		return fmt.Errorf("error with new logfile: %w", err)
+		err = fmt.Errorf("error with new logfile: %w", err)
+		l.event(&Event{Type: EventOpenError, File: l.config.Filepath, Err: err})
+
+		return err
 	}
 
 	return nil
 }
 
-// write sends a message into the log file after everyhing checks out - from a channel message.
+// write sends a message into the log file (or buffer) after everyhing checks out.
 func (l *Logger) write(bytes []byte) (int, error) {
+	if l.lowSpace && l.config.LowSpace != LowSpaceActiveOnly {
+		return l.writeLowSpace(bytes)
+	}
+
+	if l.config.FileSize > 0 && int64(len(bytes)) > l.config.FileSize {
+		switch l.config.LargeWrites {
+		case WriteLarge:
+			return l.writeLarge(bytes)
+		case SplitLarge:
+			return l.writeSplit(bytes)
+		case RejectLarge: // checkAndRotate returns an error.
+		}
+	}
+
 	err := l.checkAndRotate(int64(len(bytes)))
 	if err != nil {
 		return 0, err
 	}
 
+	if len(bytes) < l.config.BufferSize {
+		if len(l.buf)+len(bytes) > l.config.BufferSize {
+			if err = l.flush(); err != nil {
+				return 0, err
+			}
+		}
+
+		l.buf = append(l.buf, bytes...)
+		l.size += int64(len(bytes))
+		l.unsynced += int64(len(bytes))
+		l.endRecord(bytes)
+
+		return len(bytes), l.syncAfterWrite()
+	}
+
+	return l.writeFile(bytes)
+}
+
+// writeFile writes a message directly into the active file, skipping the buffer.
+func (l *Logger) writeFile(bytes []byte) (int, error) {
+	// Messages larger than the buffer skip it, but anything buffered must land first.
+	if err := l.flush(); err != nil {
+		return 0, err
+	}
+
 	size, err := l.File.Write(bytes)
 	l.size += int64(size)
+	l.unsynced += int64(size)
+	l.endRecord(bytes[:size])
 
 	if err != nil {
-		return size, fmt.Errorf("error writing log msg: %w", err)
+		err = fmt.Errorf("error writing log msg: %w", err)
+		l.event(&Event{Type: EventWriteError, File: l.config.Filepath, Size: int64(size), Err: err})
+
+		return size, err
 	}
 
-	return size, nil
+	return size, l.syncAfterWrite()
 }
 
 // checkAndRotate gets the current file's size and creation time.
@@ -282,33 +520,67 @@ func (l *Logger) checkAndRotate(size int64) error { //nolint:cyclop
 		return fmt.Errorf("%w: %d>%d", ErrWriteTooLarge, size, l.config.FileSize)
 	}
 
-	if (l.config.FileSize != 0 && l.size+size > l.config.FileSize) ||
-		(l.config.Every != 0 && time.Now().After(l.created.Add(l.config.Every))) {
-		_, err := l.rotate()
+	if reason, due := l.rotateReason(size); due && !l.deferRotate(size) {
+		_, err := l.rotate(reason)
 		if err != nil {
 			return err
 		}
+
+		l.checkSpace() // rotation made a new backup file.
 	}
 
 	return nil
 }
 
-// rotate renames the log - from a channel message.
-func (l *Logger) rotate() (int64, error) {
+// rotateReason returns true and the reason if the active file needs to be rotated.
+func (l *Logger) rotateReason(size int64) (Reason, bool) {
+	switch {
+	case l.scheduled:
+		return ReasonSchedule, true
+	case l.config.FileSize != 0 && l.size+size > l.config.FileSize:
+		return ReasonSize, true
+	case l.config.Every != 0 && time.Now().After(l.created.Add(l.config.Every)):
+		return ReasonAge, true
+	default:
+		return ReasonManual, false
+	}
+}
+
+// rotate flushes, closes and renames the log, then opens a new one.
+func (l *Logger) rotate(reason Reason) (int64, error) {
+	if l.lowSpace && l.config.LowSpace == LowSpaceActiveOnly {
+		return l.truncate()
+	}
+
 	size := l.size
+	l.scheduled = false
+	l.deferred = 0
+	l.event(&Event{Type: EventPreRotate, File: l.config.Filepath, Size: size, Reason: reason})
+
+	if l.config.CopyTruncate && l.File != nil {
+		return size, l.copyTruncate(reason)
+	}
 
 	err := l.close()
 	if err != nil {
 		return size, err
 	}
 
-	fpath, err := l.Interface.Rotate(l.config.Filepath)
+	fpath, err := l.rotatorrRotate()
 	if fpath != "" {
-		defer l.Interface.Post(l.config.Filepath, fpath)
+		defer l.rotatorrPost(fpath)
 	}
 
 	if err != nil {
-		return size, fmt.Errorf("error rotatorring: %w", err)
+		err = fmt.Errorf("error rotatorring: %w", err)
+	}
+
+	if l.rotated(fpath, size, reason, err); err != nil {
+		return size, err
+	}
+
+	if l.config.SyncPolicy != SyncNever {
+		l.syncDirs(l.config.Filepath, fpath)
 	}
 
 	l.lastOpenErr = l.openLog()
@@ -319,12 +591,38 @@ func (l *Logger) rotate() (int64, error) {
 	return size, l.lastOpenErr
 }
 
-// close closes the active log file - from a channel message.
+// flush writes the buffer into the active log file and empties it.
+// Buffered bytes were already counted in l.size when they were buffered.
+// The buffer is dropped on error to avoid growing it without limit.
+func (l *Logger) flush() error {
+	if len(l.buf) == 0 || l.File == nil {
+		return nil
+	}
+
+	size, err := l.File.Write(l.buf)
+	l.buf = l.buf[:0]
+
+	if err != nil {
+		err = fmt.Errorf("error writing buffered log msgs: %w", err)
+		l.event(&Event{Type: EventWriteError, File: l.config.Filepath, Size: int64(size), Err: err})
+
+		return err
+	}
+
+	return nil
+}
+
+// close flushes the buffer, syncs if a policy is set, and closes the active log file.
 func (l *Logger) close() error {
 	if l.File == nil {
 		return nil
 	}
 
+	flushErr := l.flush()
+	if flushErr == nil && l.config.SyncPolicy != SyncNever {
+		flushErr = l.sync()
+	}
+
 	err := l.File.Close()
 	l.File = nil
 
@@ -332,17 +630,11 @@ func (l *Logger) close() error {
 		return fmt.Errorf("closing log file %s: %w", l.config.Filepath, err)
 	}
 
-	return nil
+	return flushErr
 }
 
 // stop closes everything down.
 func (l *Logger) stop() error {
-	if l.log != nil {
-		close(l.log)
-	}
-
-	l.log = nil
-
 	return l.close()
 }
 
@@ -3,6 +3,10 @@ package rotatorr_test
 import (
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 	"golift.io/rotatorr"
+	"golift.io/rotatorr/filer"
 	"golift.io/rotatorr/introtator"
 	"golift.io/rotatorr/mocks"
 )
@@ -120,3 +125,577 @@ func TestRotateEvery(t *testing.T) {
 	mockRotatorr.EXPECT().Rotate(testFile.Name())
 	check(logger.Write([]byte(msg))) // 33
 }
+
+func TestBuffered(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRotatorr := mocks.NewMockRotatorr(mockCtrl)
+	testFile := filepath.Join(t.TempDir(), "buffered.log")
+	mockRotatorr.EXPECT().Dirs(gomock.Any())
+	//
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:      testFile,
+		FileSize:      50,
+		BufferSize:    30,
+		FlushInterval: time.Hour,
+		Rotatorr:      mockRotatorr,
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	msg := "log message" // len: 11
+	size, err := logger.Write([]byte(msg))
+	require.NoError(t, err)
+	assert.Equal(len(msg), size)
+
+	data, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Empty(data, "buffered data must not be written before a flush")
+	//
+	require.NoError(t, logger.Flush())
+	data, err = os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(msg, string(data), "flush must write buffered data")
+	// Fill the buffer past its size; the old contents get flushed first.
+	for range 3 {
+		_, err = logger.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	data, err = os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(msg+msg+msg, string(data), "a full buffer must be flushed")
+	// 55 > 50, the buffer must be flushed into the file before it is rotated.
+	mockRotatorr.EXPECT().Rotate(testFile).DoAndReturn(func(fileName string) (string, error) {
+		data, err := os.ReadFile(fileName)
+		require.NoError(t, err)
+		assert.Equal(msg+msg+msg+msg, string(data), "rotation must flush the buffer first")
+
+		return "", os.Remove(fileName)
+	})
+
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	testFile := filepath.Join(t.TempDir(), "sync.log")
+	layout := &introtator.Layout{}
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:      testFile,
+		FileSize:      50,
+		BufferSize:    30,
+		FlushInterval: time.Hour,
+		SyncPolicy:    rotatorr.SyncEveryBytes,
+		SyncSize:      20,
+		Rotatorr:      layout,
+	})
+	require.NoError(t, err)
+
+	msg := "log message" // len: 11
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Empty(data, "buffered data must not be synced before SyncSize is reached")
+	// 22 > 20, this must sync which also flushes the buffer.
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(msg+msg, string(data), "sync must flush the buffer")
+	//
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+	require.NoError(t, logger.Sync())
+
+	data, err = os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(msg+msg+msg, string(data), "sync must flush the buffer")
+	//
+	_, err = logger.Rotate()
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(filepath.Join(filepath.Dir(testFile), "sync.1.log"))
+	require.NoError(t, err)
+	assert.Equal(msg+msg+msg, string(data), "rotated file must have all the data")
+	assert.NoError(logger.Close())
+}
+
+func TestReopen(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows does not allow moving an open file")
+	}
+
+	testFile := filepath.Join(t.TempDir(), "reopen.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:    testFile,
+		ReopenCheck: 10 * time.Millisecond,
+		Rotatorr:    &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	msg := "log message"
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+	// Move the file like logrotate would; the check must notice and reopen it.
+	require.NoError(t, os.Rename(testFile, testFile+".moved"))
+	assert.Eventually(func() bool {
+		_, err := os.Stat(testFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "the moved log file must be reopened")
+
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal(msg, string(data), "writes must go to the reopened file")
+	// Delete the file and reopen it by hand.
+	require.NoError(t, os.Remove(testFile+".moved"))
+	require.NoError(t, logger.Reopen())
+	_, err = os.Stat(testFile)
+	assert.NoError(err, "reopen must leave a log file in place")
+}
+
+func TestCopyTruncate(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows does not allow moving an open file")
+	}
+
+	testFile := filepath.Join(t.TempDir(), "copytruncate.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:     testFile,
+		CopyTruncate: true,
+		Rotatorr: &introtator.Layout{PostRotate: func(fileName, newFile string) {
+			assert.Equal(testFile, fileName)
+			assert.Equal(filepath.Join(filepath.Dir(testFile), "copytruncate.1.log"), newFile)
+		}},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	// This is another writer with its own file descriptor, like a child process.
+	child, err := os.OpenFile(testFile, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+
+	defer child.Close()
+
+	msg := "log message"
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+	_, err = child.Write([]byte(msg))
+	require.NoError(t, err)
+	//
+	size, err := logger.Rotate()
+	require.NoError(t, err)
+	assert.Equal(int64(len(msg)), size)
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(testFile), "copytruncate.1.log"))
+	require.NoError(t, err)
+	assert.Equal(msg+msg, string(data), "the backup file must contain everything written")
+	// Both writers must still be writing into the active file.
+	_, err = child.Write([]byte("child"))
+	require.NoError(t, err)
+	_, err = logger.Write([]byte("logger"))
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal("childlogger", string(data), "the active file must be truncated and still in use")
+}
+
+// failRestore fails to move the active file back into place during copytruncate.
+type failRestore struct{ filer.Filer }
+
+func (f failRestore) Rename(oldpath, newpath string) error {
+	if strings.HasSuffix(oldpath, ".copytruncate") {
+		return os.ErrPermission
+	}
+
+	return f.Filer.Rename(oldpath, newpath) //nolint:wrapcheck
+}
+
+func TestCopyTruncateRestoreError(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows does not allow moving an open file")
+	}
+
+	testFile := filepath.Join(t.TempDir(), "restore.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:     testFile,
+		CopyTruncate: true,
+		Rotatorr:     &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	logger.Filer = failRestore{Filer: filer.Default()}
+	_, err = logger.Write([]byte("first"))
+	require.NoError(t, err)
+	_, err = logger.Rotate()
+	require.ErrorIs(t, err, os.ErrPermission)
+	assert.Nil(logger.File, "the stashed file must be closed")
+	// The next write must reopen Filepath, not write into the stashed file.
+	_, err = logger.Write([]byte("second"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal("second", string(data))
+}
+
+func TestCopyTruncateRecover(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	// The app exited before copytruncate moved the active file back.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".lost.log.copytruncate"), []byte("first"), 0o600))
+	// The active file was moved back, but the stash from a failed restore was left behind.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".both.log.copytruncate"), []byte("first"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "both.log"), []byte("second"), 0o600))
+
+	for name, want := range map[string]string{"lost.log": "firstthird", "both.log": "firstsecondthird"} {
+		logger, err := rotatorr.New(&rotatorr.Config{
+			Filepath:     filepath.Join(dir, name),
+			CopyTruncate: true,
+			Rotatorr:     &introtator.Layout{},
+		})
+		require.NoError(t, err)
+
+		_, err = logger.Write([]byte("third"))
+		require.NoError(t, err)
+		require.NoError(t, logger.Close())
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(want, string(data), "the stash must be restored without losing data")
+		assert.NoFileExists(filepath.Join(dir, "."+name+".copytruncate"))
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	testFile := filepath.Join(t.TempDir(), "schedule.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: testFile,
+		Schedule: rotatorr.ScheduleFunc(func(after time.Time) time.Time {
+			return after.Add(20 * time.Millisecond)
+		}),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	_, err = logger.Write([]byte("log message"))
+	require.NoError(t, err)
+	// Nothing else is written, the timer must rotate the file.
+	assert.Eventually(func() bool {
+		_, err := os.Stat(filepath.Join(filepath.Dir(testFile), "schedule.1.log"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "the schedule must rotate the file")
+	// Empty files are not rotated.
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(filepath.Dir(testFile), "schedule.2.log"))
+	assert.ErrorIs(err, os.ErrNotExist, "empty files must not be rotated")
+}
+
+func TestScheduleEnds(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir   = t.TempDir()
+		calls atomic.Int32
+	)
+
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "ends.log"),
+		Schedule: rotatorr.ScheduleFunc(func(after time.Time) time.Time {
+			if calls.Add(1) == 1 {
+				return after.Add(20 * time.Millisecond)
+			}
+
+			return time.Time{} // cron returns zero when the spec never matches again.
+		}),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	_, err = logger.Write([]byte("log message"))
+	require.NoError(t, err)
+	assert.Eventually(func() bool {
+		_, err := os.Stat(filepath.Join(dir, "ends.1.log"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "the schedule must rotate the file")
+
+	_, err = logger.Write([]byte("log message"))
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.NoFileExists(filepath.Join(dir, "ends.2.log"), "a zero Next must stop the schedule")
+	assert.EqualValues(2, calls.Load(), "a zero Next must not be retried")
+
+	past, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "past.log"),
+		Schedule: rotatorr.ScheduleFunc(func(after time.Time) time.Time { return after.Add(-time.Hour) }),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer past.Close()
+
+	_, err = past.Write([]byte("log message"))
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.NoFileExists(filepath.Join(dir, "past.1.log"), "a Next in the past must not rotate")
+}
+
+func TestLargeWrites(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	msg := "log message"                     // len: 11
+	large := msg + msg + msg + msg + msg[:6] // len: 50
+	//
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:    filepath.Join(dir, "split.log"),
+		FileSize:    20,
+		LargeWrites: rotatorr.SplitLarge,
+		Rotatorr:    &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+
+	size, err := logger.Write([]byte(large))
+	require.NoError(t, err)
+	assert.Equal(len(large), size, "the whole message must be written")
+	require.NoError(t, logger.Close())
+
+	var data []byte
+
+	for _, name := range []string{"split.3.log", "split.2.log", "split.1.log", "split.log"} {
+		file, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.LessOrEqual(len(file), 20, "split files must not exceed the max size")
+
+		data = append(data, file...)
+	}
+
+	assert.Equal(msg+large, string(data), "the split message must be intact across files")
+	//
+	logger, err = rotatorr.New(&rotatorr.Config{
+		Filepath:    filepath.Join(dir, "large.log"),
+		FileSize:    20,
+		LargeWrites: rotatorr.WriteLarge,
+		Rotatorr:    &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	_, err = logger.Write([]byte(msg))
+	require.NoError(t, err)
+
+	size, err = logger.Write([]byte(large))
+	require.NoError(t, err)
+	assert.Equal(len(large), size, "the whole message must be written")
+	require.NoError(t, logger.Close())
+
+	data, err = os.ReadFile(filepath.Join(dir, "large.log"))
+	require.NoError(t, err)
+	assert.Equal(large, string(data), "the large message must be written into a fresh file")
+
+	data, err = os.ReadFile(filepath.Join(dir, "large.1.log"))
+	require.NoError(t, err)
+	assert.Equal(msg, string(data), "the previous file must be rotated")
+}
+
+func TestDelimiter(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:  filepath.Join(dir, "record.log"),
+		FileSize:  20,
+		Delimiter: []byte("\n"),
+		Rotatorr:  &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	for _, msg := range []string{
+		"first record!\n", // 14
+		"second ",         // 14+7 > 20, rotate.
+		"record is ",      // 17
+		"long\n",          // 22 > 20, but the record is not finished.
+		"third\n",         // 22+6 > 20, rotate.
+	} {
+		_, err = logger.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, logger.Close())
+
+	for name, expect := range map[string]string{
+		"record.2.log": "first record!\n",
+		"record.1.log": "second record is long\n",
+		"record.log":   "third\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(expect, string(data), "records must not straddle files")
+	}
+}
+
+func TestClosed(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	testFile := filepath.Join(t.TempDir(), "closed.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath:   testFile,
+		BufferSize: 100,
+		Rotatorr:   &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	_, err = logger.Write([]byte("log message"))
+	require.NoError(t, err)
+	require.NoError(t, logger.Shutdown(t.Context()))
+	require.NoError(t, logger.Close(), "closing twice must be safe")
+
+	data, err := os.ReadFile(testFile)
+	require.NoError(t, err)
+	assert.Equal("log message", string(data), "shutdown must drain the buffer")
+
+	_, err = logger.Write([]byte("late message"))
+	require.ErrorIs(t, err, rotatorr.ErrClosed)
+	_, err = logger.Rotate()
+	require.ErrorIs(t, err, rotatorr.ErrClosed)
+	require.ErrorIs(t, logger.Flush(), rotatorr.ErrClosed)
+	require.ErrorIs(t, logger.Sync(), rotatorr.ErrClosed)
+	require.ErrorIs(t, logger.Reopen(), rotatorr.ErrClosed)
+}
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir    = t.TempDir()
+		events []*rotatorr.Event
+	)
+
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "events.log"),
+		FileSize: 20,
+		OnEvent:  func(event *rotatorr.Event) { events = append(events, event) },
+		Rotatorr: &introtator.Layout{FileCount: 1},
+	})
+	require.NoError(t, err)
+
+	msg := "log message" // len: 11
+	for range 2 {        // the second write rotates.
+		_, err = logger.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	_, err = logger.Rotate()
+	require.NoError(t, err)
+	require.NoError(t, logger.Close())
+	require.Len(t, events, 5)
+	//
+	assert.Equal(rotatorr.EventPreRotate, events[0].Type)
+	assert.Equal(rotatorr.ReasonSize, events[0].Reason)
+	assert.Equal(rotatorr.EventPostRotate, events[1].Type)
+	assert.Equal(rotatorr.ReasonSize, events[1].Reason)
+	assert.Equal(filepath.Join(dir, "events.1.log"), events[1].NewFile)
+	assert.Equal(int64(len(msg)), events[1].Size)
+	require.NoError(t, events[1].Err)
+	assert.Equal(rotatorr.EventPreRotate, events[2].Type)
+	assert.Equal(rotatorr.ReasonManual, events[2].Reason)
+	assert.Equal(rotatorr.EventDelete, events[3].Type, "retention deletes must be reported")
+	assert.Equal(filepath.Join(dir, "events.2.log"), events[3].File)
+	assert.Equal(rotatorr.EventPostRotate, events[4].Type)
+	// A log file path that cannot be created must produce an open error.
+	events = nil
+	logger = rotatorr.NewMust(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "events.log", "nope.log"),
+		OnEvent:  func(event *rotatorr.Event) { events = append(events, event) },
+		Rotatorr: &introtator.Layout{},
+	})
+
+	require.NoError(t, logger.Close())
+	require.Len(t, events, 1)
+	assert.Equal(rotatorr.EventOpenError, events[0].Type)
+	assert.Error(events[0].Err)
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	testFile := filepath.Join(t.TempDir(), "stats.log")
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: testFile,
+		FileSize: 20,
+		Rotatorr: &introtator.Layout{FileCount: 1},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	msg := "log message" // len: 11
+	for range 3 {        // the second and third writes rotate.
+		_, err = logger.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	_, err = logger.Write([]byte(msg + msg))
+	require.ErrorIs(t, err, rotatorr.ErrWriteTooLarge)
+	_, err = logger.Rotate()
+	require.NoError(t, err)
+
+	stats := logger.Stats()
+	assert.Equal(testFile, stats.FilePath)
+	assert.Equal(int64(0), stats.FileSize)
+	assert.Equal(uint64(4), stats.Writes)
+	assert.Equal(uint64(len(msg)*3), stats.Bytes)
+	assert.Equal(map[rotatorr.Reason]uint64{rotatorr.ReasonSize: 2, rotatorr.ReasonManual: 1}, stats.Rotations)
+	assert.Equal(uint64(2), stats.Deletes)
+	assert.WithinDuration(time.Now(), stats.LastRotation, time.Second)
+	assert.Positive(stats.RotateTime)
+	assert.NoError(stats.LastError)
+	assert.Less(stats.FileAge, time.Second)
+}
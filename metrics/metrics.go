@@ -0,0 +1,236 @@
+// Package metrics publishes stats from one or many named rotatorr Loggers, and the
+// results of compressor reports. Metrics are available with expvar, and as a
+// Prometheus text-exposition http.Handler. No client library is required.
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+)
+
+// Collector holds named loggers and compression totals. Use New() to get one.
+type Collector struct {
+	mu       sync.Mutex
+	loggers  map[string]*rotatorr.Logger
+	compress map[string]*Compression
+}
+
+// Compression holds the totals from compressor reports for one logger name.
+type Compression struct {
+	Files    uint64  `json:"files"`
+	Failures uint64  `json:"failures"`
+	BytesIn  int64   `json:"bytesIn"`
+	BytesOut int64   `json:"bytesOut"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// Stats is a Logger's rotatorr.Stats ready for JSON. Durations are in seconds,
+// rotations are keyed by reason name, and the last error is a string.
+type Stats struct {
+	FilePath      string            `json:"filePath"`
+	FileSize      int64             `json:"fileSize"`
+	FileAge       float64           `json:"fileAgeSeconds"`
+	Writes        uint64            `json:"writes"`
+	Bytes         uint64            `json:"bytes"`
+	Rotations     map[string]uint64 `json:"rotations"`
+	LastRotation  time.Time         `json:"lastRotation,omitzero"`
+	OpenErrors    uint64            `json:"openErrors"`
+	WriteErrors   uint64            `json:"writeErrors"`
+	RotateErrors  uint64            `json:"rotateErrors"`
+	DeleteErrors  uint64            `json:"deleteErrors"`
+	Deletes       uint64            `json:"deletes"`
+	LastError     string            `json:"lastError,omitempty"`
+	LastErrorAt   time.Time         `json:"lastErrorAt,omitzero"`
+	RotateSeconds float64           `json:"rotateSeconds"`
+	PostSeconds   float64           `json:"postSeconds"`
+}
+
+// Logger is the data published with expvar for each logger name.
+// Stats is nil for names that only have compression reports.
+type Logger struct {
+	Stats       *Stats       `json:"stats,omitempty"`
+	Compression *Compression `json:"compression,omitempty"`
+}
+
+// newStats converts a Logger's stats for publishing.
+func newStats(stats *rotatorr.Stats) *Stats {
+	published := &Stats{
+		FilePath:      stats.FilePath,
+		FileSize:      stats.FileSize,
+		FileAge:       stats.FileAge.Seconds(),
+		Writes:        stats.Writes,
+		Bytes:         stats.Bytes,
+		Rotations:     make(map[string]uint64, len(stats.Rotations)),
+		LastRotation:  stats.LastRotation,
+		OpenErrors:    stats.OpenErrors,
+		WriteErrors:   stats.WriteErrors,
+		RotateErrors:  stats.RotateErrors,
+		DeleteErrors:  stats.DeleteErrors,
+		Deletes:       stats.Deletes,
+		LastErrorAt:   stats.LastErrorAt,
+		RotateSeconds: stats.RotateTime.Seconds(),
+		PostSeconds:   stats.PostTime.Seconds(),
+	}
+
+	for reason, count := range stats.Rotations {
+		published.Rotations[reason.String()] += count
+	}
+
+	if stats.LastError != nil {
+		published.LastError = stats.LastError.Error()
+	}
+
+	return published
+}
+
+// New returns an empty Collector.
+func New() *Collector {
+	return &Collector{
+		loggers:  make(map[string]*rotatorr.Logger),
+		compress: make(map[string]*Compression),
+	}
+}
+
+// Add starts collecting stats from a logger with the provided name.
+// Adding the same name again replaces the logger.
+func (c *Collector) Add(name string, logger *rotatorr.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loggers[name] = logger
+}
+
+// Remove stops collecting stats and compression totals for a logger.
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.loggers, name)
+	delete(c.compress, name)
+}
+
+// Report adds a compressor report to the totals for a logger name.
+func (c *Collector) Report(name string, report *compressor.Report) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	comp := c.compress[name]
+	if comp == nil {
+		comp = &Compression{}
+		c.compress[name] = comp
+	}
+
+	comp.Files++
+	comp.Seconds += report.Elapsed.Seconds()
+
+	if report.Error != nil {
+		comp.Failures++
+		return
+	}
+
+	comp.BytesIn += report.OldSize
+	comp.BytesOut += report.NewSize
+}
+
+// Reporter returns a callback for compressor.CompressBackground that adds reports to a logger name.
+func (c *Collector) Reporter(name string) func(report *compressor.Report) {
+	return func(report *compressor.Report) { c.Report(name, report) }
+}
+
+// Publish makes the metrics available with expvar using the provided variable name.
+// Like expvar.Publish, this panics if the name is already in use.
+func (c *Collector) Publish(varName string) {
+	expvar.Publish(varName, expvar.Func(func() any { return c.Loggers() }))
+}
+
+// Loggers returns the published data for every logger name.
+func (c *Collector) Loggers() map[string]*Logger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	loggers := make(map[string]*Logger, len(c.loggers)+len(c.compress))
+
+	for name, logger := range c.loggers {
+		loggers[name] = &Logger{Stats: newStats(logger.Stats())}
+	}
+
+	for name, comp := range c.compress {
+		if loggers[name] == nil {
+			loggers[name] = &Logger{}
+		}
+
+		copied := *comp
+		loggers[name].Compression = &copied
+	}
+
+	return loggers
+}
+
+// String satisfies the expvar.Var interface, in case you want to use expvar.Map.
+func (c *Collector) String() string {
+	data, _ := json.Marshal(c.Loggers())
+	return string(data)
+}
+
+// ServeHTTP writes the metrics in Prometheus text exposition format.
+func (c *Collector) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WritePrometheus(resp)
+}
+
+// WritePrometheus writes the metrics in Prometheus text exposition format.
+func (c *Collector) WritePrometheus(writer io.Writer) error {
+	loggers := c.Loggers()
+	names := make([]string, 0, len(loggers))
+
+	for name := range loggers {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var buf strings.Builder
+
+	for _, metric := range metrics {
+		var lines []string
+
+		for _, name := range names {
+			for _, point := range metric.points(loggers[name]) {
+				lines = append(lines, fmt.Sprintf(`%s{logger="%s"%s} %v`, metric.name, labelEscaper.Replace(name), point.labels, point.value))
+			}
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		buf.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+
+	_, err := io.WriteString(writer, buf.String())
+	if err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+
+	return nil
+}
+
+// labelEscaper makes a string safe for a Prometheus label value.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`) //nolint:gochecknoglobals
+
+// Our Collector must satisfy an expvar.Var and an http.Handler.
+var (
+	_ expvar.Var   = (*Collector)(nil)
+	_ http.Handler = (*Collector)(nil)
+)
@@ -0,0 +1,86 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
+	"golift.io/rotatorr/introtator"
+	"golift.io/rotatorr/metrics"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(t.TempDir(), "app.log"),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	_, err = logger.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	_, err = logger.Rotate()
+	require.NoError(t, err)
+
+	collector := metrics.New()
+	collector.Add(`app "one"`, logger)
+	report := collector.Reporter(`app "one"`)
+	report(&compressor.Report{OldSize: 100, NewSize: 10, Elapsed: time.Second})
+	report(&compressor.Report{Elapsed: time.Second, Error: errors.New("bad")}) //nolint:err113
+
+	// This logger cannot open its file, so it has a last error.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o600))
+
+	broken := rotatorr.NewMust(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "file", "broken.log"),
+		Rotatorr: &introtator.Layout{},
+	})
+	defer broken.Close()
+
+	collector.Add("broken", broken)
+	collector.Publish("rotatorr_test")
+
+	loggers := map[string]*metrics.Logger{}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("rotatorr_test").String()), &loggers))
+	require.Contains(t, loggers, `app "one"`)
+	require.Contains(t, loggers, "broken")
+	assert.NotEmpty(loggers["broken"].Stats.LastError)
+	assert.EqualValues(1, loggers[`app "one"`].Stats.Writes)
+	assert.Equal(map[string]uint64{"manual": 1}, loggers[`app "one"`].Stats.Rotations)
+	assert.InDelta(2, loggers[`app "one"`].Compression.Seconds, 0.01)
+	assert.EqualValues(2, loggers[`app "one"`].Compression.Files)
+	assert.EqualValues(1, loggers[`app "one"`].Compression.Failures)
+	assert.EqualValues(100, loggers[`app "one"`].Compression.BytesIn)
+
+	resp := httptest.NewRecorder()
+	collector.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := resp.Body.String()
+
+	assert.Contains(resp.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(body, "# TYPE rotatorr_writes_total counter\nrotatorr_writes_total{logger=\"app \\\"one\\\"\"} 1\n")
+	assert.Contains(body, `rotatorr_written_bytes_total{logger="app \"one\""} 6`)
+	assert.Contains(body, `rotatorr_rotations_total{logger="app \"one\"",reason="manual"} 1`)
+	assert.Contains(body, `rotatorr_errors_total{logger="app \"one\"",type="write"} 0`)
+	assert.Contains(body, `rotatorr_compression_failures_total{logger="app \"one\""} 1`)
+	assert.Contains(body, `rotatorr_compression_output_bytes_total{logger="app \"one\""} 10`)
+	assert.Contains(body, `rotatorr_compression_seconds_total{logger="app \"one\""} 2`)
+
+	collector.Remove(`app "one"`)
+	collector.Remove("broken")
+	assert.Empty(collector.Loggers())
+}
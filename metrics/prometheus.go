@@ -0,0 +1,117 @@
+package metrics
+
+import "slices"
+
+// metric describes one Prometheus metric, and how to get its values from a Logger.
+type metric struct {
+	name   string
+	help   string
+	kind   string
+	points func(logger *Logger) []point
+}
+
+// point is one value in a metric, with optional extra labels.
+type point struct {
+	labels string
+	value  any
+}
+
+// stat returns a single point from a Logger's stats, or nothing if the logger has no stats.
+func stat(value func(stats *Stats) any) func(logger *Logger) []point {
+	return func(logger *Logger) []point {
+		if logger.Stats == nil {
+			return nil
+		}
+
+		return []point{{value: value(logger.Stats)}}
+	}
+}
+
+// compression returns a single point from a Logger's compression totals, if there are any.
+func compression(value func(comp *Compression) any) func(logger *Logger) []point {
+	return func(logger *Logger) []point {
+		if logger.Compression == nil {
+			return nil
+		}
+
+		return []point{{value: value(logger.Compression)}}
+	}
+}
+
+// rotations returns one point per rotation reason.
+func rotations(logger *Logger) []point {
+	if logger.Stats == nil {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(logger.Stats.Rotations))
+	for reason := range logger.Stats.Rotations {
+		reasons = append(reasons, reason)
+	}
+
+	slices.Sort(reasons)
+
+	points := make([]point, len(reasons))
+	for idx, reason := range reasons {
+		points[idx] = point{labels: `,reason="` + labelEscaper.Replace(reason) + `"`, value: logger.Stats.Rotations[reason]}
+	}
+
+	return points
+}
+
+// errorCounts returns one point per error type.
+func errorCounts(logger *Logger) []point {
+	if logger.Stats == nil {
+		return nil
+	}
+
+	return []point{
+		{labels: `,type="open"`, value: logger.Stats.OpenErrors},
+		{labels: `,type="write"`, value: logger.Stats.WriteErrors},
+		{labels: `,type="rotate"`, value: logger.Stats.RotateErrors},
+		{labels: `,type="delete"`, value: logger.Stats.DeleteErrors},
+	}
+}
+
+// metrics is the list of everything written by WritePrometheus.
+//
+//nolint:gochecknoglobals,lll
+var metrics = []*metric{
+	{"rotatorr_file_size_bytes", "Size of the active log file.", "gauge",
+		stat(func(s *Stats) any { return s.FileSize })},
+	{"rotatorr_file_age_seconds", "Age of the active log file.", "gauge",
+		stat(func(s *Stats) any { return s.FileAge })},
+	{"rotatorr_writes_total", "Number of log writes.", "counter",
+		stat(func(s *Stats) any { return s.Writes })},
+	{"rotatorr_written_bytes_total", "Number of bytes written to log files.", "counter",
+		stat(func(s *Stats) any { return s.Bytes })},
+	{"rotatorr_rotations_total", "Number of log file rotations by reason.", "counter", rotations},
+	{"rotatorr_last_rotation_timestamp_seconds", "Unix time of the last log file rotation.", "gauge",
+		stat(func(s *Stats) any { return lastRotation(s) })},
+	{"rotatorr_errors_total", "Number of log file errors by type.", "counter", errorCounts},
+	{"rotatorr_deletes_total", "Number of backup files deleted by retention.", "counter",
+		stat(func(s *Stats) any { return s.Deletes })},
+	{"rotatorr_rotate_seconds_total", "Time spent rotating log files.", "counter",
+		stat(func(s *Stats) any { return s.RotateSeconds })},
+	{"rotatorr_post_seconds_total", "Time spent in post-rotate hooks.", "counter",
+		stat(func(s *Stats) any { return s.PostSeconds })},
+	{"rotatorr_compressions_total", "Number of compressed backup files.", "counter",
+		compression(func(c *Compression) any { return c.Files })},
+	{"rotatorr_compression_failures_total", "Number of failed backup file compressions.", "counter",
+		compression(func(c *Compression) any { return c.Failures })},
+	{"rotatorr_compression_input_bytes_total", "Number of bytes read by compression.", "counter",
+		compression(func(c *Compression) any { return c.BytesIn })},
+	{"rotatorr_compression_output_bytes_total", "Number of bytes written by compression.", "counter",
+		compression(func(c *Compression) any { return c.BytesOut })},
+	{"rotatorr_compression_seconds_total", "Time spent compressing backup files.", "counter",
+		compression(func(c *Compression) any { return c.Seconds })},
+}
+
+// lastRotation returns the unix time of the last rotation, or zero if there wasn't one.
+func lastRotation(stats *Stats) float64 {
+	if stats.LastRotation.IsZero() {
+		return 0
+	}
+
+	return float64(stats.LastRotation.UnixNano()) / 1e9 //nolint:mnd
+}
@@ -127,3 +127,18 @@ func (mr *MockFilerMockRecorder) Stat(filename any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFiler)(nil).Stat), filename)
 }
+
+// Statfs mocks base method.
+func (m *MockFiler) Statfs(path string) (*filer.DiskSpace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statfs", path)
+	ret0, _ := ret[0].(*filer.DiskSpace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Statfs indicates an expected call of Statfs.
+func (mr *MockFilerMockRecorder) Statfs(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statfs", reflect.TypeOf((*MockFiler)(nil).Statfs), path)
+}
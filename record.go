@@ -0,0 +1,37 @@
+package rotatorr
+
+import "bytes"
+
+// deferRotate returns true if a rotation should wait for the current record to end.
+// Waiting is bounded by Config.MaxOverflow bytes.
+func (l *Logger) deferRotate(size int64) bool {
+	if len(l.config.Delimiter) == 0 || !l.midRecord || l.deferred+size > l.config.MaxOverflow {
+		return false
+	}
+
+	l.deferred += size
+
+	return true
+}
+
+// endRecord keeps track of whether the latest write ended with the record delimiter.
+func (l *Logger) endRecord(data []byte) {
+	if len(l.config.Delimiter) != 0 && len(data) != 0 {
+		l.midRecord = !bytes.HasSuffix(data, l.config.Delimiter)
+	}
+}
+
+// lastRecordEnd returns the length of data up to and including the last record delimiter.
+// Returns 0 if there is no Delimiter, or data does not contain one.
+func (l *Logger) lastRecordEnd(data []byte) int {
+	if len(l.config.Delimiter) == 0 {
+		return 0
+	}
+
+	idx := bytes.LastIndex(data, l.config.Delimiter)
+	if idx < 0 {
+		return 0
+	}
+
+	return idx + len(l.config.Delimiter)
+}
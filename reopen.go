@@ -0,0 +1,55 @@
+package rotatorr
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Reopen flushes and closes the active log file, then opens Config.Filepath again.
+// Use this after another process (like logrotate) moves or deletes the log file.
+// This does not call the Rotatorr.
+func (l *Logger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	return l.reopen()
+}
+
+// reopen closes and opens the log file without rotating it.
+func (l *Logger) reopen() error {
+	err := l.close()
+
+	l.lastOpened = time.Now()
+	l.lastOpenErr = l.openLog()
+
+	if err != nil {
+		return err
+	}
+
+	return l.lastOpenErr
+}
+
+// checkMoved compares the open file to the file at Config.Filepath, and reopens
+// the log file if they differ. This catches the file being moved or deleted.
+func (l *Logger) checkMoved() error {
+	if l.File == nil {
+		return nil
+	}
+
+	openInfo, err := l.File.Stat()
+	if err != nil {
+		return fmt.Errorf("checking log file %s: %w", l.config.Filepath, err)
+	}
+
+	pathInfo, err := l.Stat(l.config.Filepath)
+	if err == nil && os.SameFile(openInfo, pathInfo.FileInfo) {
+		return nil
+	}
+
+	return l.reopen()
+}
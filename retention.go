@@ -0,0 +1,28 @@
+package rotatorr
+
+import "time"
+
+// Backup is a rotated backup log file. A list of these is passed to a Retention policy.
+type Backup struct {
+	Path string
+	Time time.Time // When the file was rotated. From the file name, or the modification time.
+	Size int64     // Size of the (possibly compressed) file in bytes. Zero if unknown.
+}
+
+// Retention decides which backup files are deleted after a rotation. Both included
+// layouts accept a Retention. Built-in policies are in the retention package.
+type Retention interface {
+	// Expired receives every backup file, oldest first, and returns the backups to delete.
+	Expired(backups []*Backup) []*Backup
+}
+
+// RetentionFunc allows using a plain function as a Retention policy.
+type RetentionFunc func(backups []*Backup) []*Backup
+
+// Expired satisfies the Retention interface.
+func (f RetentionFunc) Expired(backups []*Backup) []*Backup {
+	return f(backups)
+}
+
+// Our type must satisfy a Retention.
+var _ Retention = RetentionFunc(nil)
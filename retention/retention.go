@@ -0,0 +1,123 @@
+// Package retention provides Retention policies for the rotatorr layouts.
+// Every policy receives backup files sorted oldest first, and returns the backups
+// that should be deleted. Combine policies with AllOf and AnyOf. Example that keeps
+// at most 10 backups, deletes backups older than a week, and keeps 1GB at most:
+//
+//	policy := retention.AnyOf{retention.Count(10), retention.Age(7 * 24 * time.Hour), retention.Size(1 << 30)}
+package retention
+
+import (
+	"time"
+
+	"golift.io/rotatorr"
+)
+
+// Count keeps this many of the newest backups. Zero keeps every backup.
+type Count int
+
+// Age keeps backups younger than this. Zero keeps every backup.
+type Age time.Duration
+
+// Size keeps the newest backups whose total size is not over this many bytes. Zero keeps every backup.
+type Size int64
+
+// AllOf deletes backups that every policy in the list deletes.
+type AllOf []rotatorr.Retention
+
+// AnyOf deletes backups that any policy in the list deletes.
+type AnyOf []rotatorr.Retention
+
+// Expired satisfies the rotatorr.Retention interface.
+func (c Count) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	if c < 1 || len(backups) <= int(c) {
+		return nil
+	}
+
+	return backups[:len(backups)-int(c)]
+}
+
+// Expired satisfies the rotatorr.Retention interface.
+func (a Age) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	if a <= 0 {
+		return nil
+	}
+
+	var expired []*rotatorr.Backup
+
+	for _, backup := range backups {
+		if time.Since(backup.Time) >= time.Duration(a) {
+			expired = append(expired, backup)
+		}
+	}
+
+	return expired
+}
+
+// Expired satisfies the rotatorr.Retention interface.
+func (s Size) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	if s < 1 {
+		return nil
+	}
+
+	var total int64
+
+	for idx := len(backups) - 1; idx >= 0; idx-- {
+		if total += backups[idx].Size; total > int64(s) {
+			return backups[:idx+1]
+		}
+	}
+
+	return nil
+}
+
+// Expired satisfies the rotatorr.Retention interface.
+func (a AllOf) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	if len(a) == 0 {
+		return nil
+	}
+
+	counts := make(map[*rotatorr.Backup]int)
+
+	for _, policy := range a {
+		for _, backup := range policy.Expired(backups) {
+			counts[backup]++
+		}
+	}
+
+	return filter(backups, func(backup *rotatorr.Backup) bool { return counts[backup] == len(a) })
+}
+
+// Expired satisfies the rotatorr.Retention interface.
+func (a AnyOf) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	expired := make(map[*rotatorr.Backup]bool)
+
+	for _, policy := range a {
+		for _, backup := range policy.Expired(backups) {
+			expired[backup] = true
+		}
+	}
+
+	return filter(backups, func(backup *rotatorr.Backup) bool { return expired[backup] })
+}
+
+// filter returns the backups that match, in their original order.
+func filter(backups []*rotatorr.Backup, match func(backup *rotatorr.Backup) bool) []*rotatorr.Backup {
+	var matched []*rotatorr.Backup
+
+	for _, backup := range backups {
+		if match(backup) {
+			matched = append(matched, backup)
+		}
+	}
+
+	return matched
+}
+
+// Our types must satisfy a rotatorr.Retention.
+var (
+	_ rotatorr.Retention = Count(0)
+	_ rotatorr.Retention = Age(0)
+	_ rotatorr.Retention = Size(0)
+	_ rotatorr.Retention = AllOf(nil)
+	_ rotatorr.Retention = AnyOf(nil)
+)
@@ -0,0 +1,130 @@
+package retention_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/retention"
+)
+
+// testBackups returns 5 backups, oldest first. Each is an hour newer and 10 bytes larger than the last.
+func testBackups() []*rotatorr.Backup {
+	backups := make([]*rotatorr.Backup, 5)
+
+	for idx := range backups {
+		backups[idx] = &rotatorr.Backup{
+			Path: string(rune('a' + idx)),
+			Time: time.Now().Add(-time.Duration(len(backups)-idx) * time.Hour),
+			Size: int64(idx+1) * 10,
+		}
+	}
+
+	return backups
+}
+
+// paths returns the paths of the backups, to make comparisons readable.
+func paths(backups []*rotatorr.Backup) []string {
+	list := []string{}
+	for _, backup := range backups {
+		list = append(list, backup.Path)
+	}
+
+	return list
+}
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	backups := testBackups()
+
+	assert.Equal([]string{}, paths(retention.Count(0).Expired(backups)), "zero must keep everything")
+	assert.Equal([]string{"a", "b"}, paths(retention.Count(3).Expired(backups)))
+	assert.Equal([]string{}, paths(retention.Count(9).Expired(backups)))
+	assert.Equal([]string{}, paths(retention.Age(0).Expired(backups)), "zero must keep everything")
+	assert.Equal([]string{"a", "b"}, paths(retention.Age(210*time.Minute).Expired(backups)))
+	assert.Equal([]string{}, paths(retention.Size(0).Expired(backups)), "zero must keep everything")
+	assert.Equal([]string{"a", "b", "c"}, paths(retention.Size(90).Expired(backups)))
+	assert.Equal([]string{"a", "b", "c", "d"}, paths(retention.Size(89).Expired(backups)))
+	assert.Equal([]string{}, paths(retention.Size(150).Expired(backups)))
+
+	anyOf := retention.AnyOf{retention.Count(4), retention.Age(210 * time.Minute)}
+	assert.Equal([]string{"a", "b"}, paths(anyOf.Expired(backups)))
+
+	allOf := retention.AllOf{retention.Count(4), retention.Age(210 * time.Minute)}
+	assert.Equal([]string{"a"}, paths(allOf.Expired(backups)))
+	assert.Equal([]string{}, paths(retention.AllOf{}.Expired(backups)), "empty must keep everything")
+
+	custom := rotatorr.RetentionFunc(func(backups []*rotatorr.Backup) []*rotatorr.Backup {
+		return backups[len(backups)-1:] // silly, but possible.
+	})
+	assert.Equal([]string{"b", "e"}, paths(retention.AnyOf{custom, retention.Count(3), retention.Count(0)}.Expired(backups[1:])))
+}
+
+func TestTiered(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	// date returns a backup with the time as its path.
+	date := func(year int, month time.Month, day, hour, minute int) *rotatorr.Backup {
+		when := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+		return &rotatorr.Backup{Path: when.Format("2006-01-02T15:04"), Time: when}
+	}
+
+	// These are sorted oldest first, like the layouts provide them.
+	backups := []*rotatorr.Backup{
+		date(2019, 6, 1, 0, 0),
+		date(2019, 12, 1, 0, 0),
+		date(2020, 1, 5, 10, 0), // Sunday
+		date(2020, 1, 6, 8, 0),  // Monday
+		date(2020, 1, 6, 8, 30),
+		date(2020, 1, 6, 9, 10),
+		date(2020, 1, 20, 0, 0),
+		date(2020, 2, 3, 0, 0),
+	}
+
+	tests := []struct {
+		period retention.Period
+		kept   []string
+	}{
+		{retention.Hourly, []string{"2019-06-01T00:00", "2019-12-01T00:00", "2020-01-05T10:00", "2020-01-06T08:30",
+			"2020-01-06T09:10", "2020-01-20T00:00", "2020-02-03T00:00"}},
+		{retention.Daily, []string{"2019-06-01T00:00", "2019-12-01T00:00", "2020-01-05T10:00", "2020-01-06T09:10",
+			"2020-01-20T00:00", "2020-02-03T00:00"}},
+		{retention.Weekly, []string{"2019-06-01T00:00", "2019-12-01T00:00", "2020-01-05T10:00", "2020-01-06T09:10",
+			"2020-01-20T00:00", "2020-02-03T00:00"}},
+		{retention.Monthly, []string{"2019-06-01T00:00", "2019-12-01T00:00", "2020-01-20T00:00", "2020-02-03T00:00"}},
+		{retention.Yearly, []string{"2019-12-01T00:00", "2020-02-03T00:00"}},
+	}
+
+	for _, test := range tests {
+		expired := retention.Tiered{{Period: test.period}}.Expired(backups)
+		kept := []string{}
+
+		for _, backup := range backups {
+			if !slices.Contains(expired, backup) {
+				kept = append(kept, backup.Path)
+			}
+		}
+
+		assert.Equal(test.kept, kept, "wrong backups kept for period %d", test.period)
+	}
+
+	// Tiers combine, and each one only looks at backups younger than Keep.
+	now := time.Now()
+	recent := []*rotatorr.Backup{
+		{Path: "old", Time: now.AddDate(0, 0, -400)},
+		{Path: "hour1", Time: now.Add(-2 * time.Hour)},
+		{Path: "hour2", Time: now.Add(-time.Hour)},
+	}
+	tiered := retention.Tiered{
+		{Period: retention.Hourly, Keep: 48 * time.Hour},
+		{Period: retention.Monthly, Keep: 365 * 24 * time.Hour},
+	}
+
+	assert.Equal([]string{"old"}, paths(tiered.Expired(recent)))
+	assert.Equal([]string{}, paths(retention.Tiered{}.Expired(recent)), "empty must keep everything")
+}
@@ -0,0 +1,87 @@
+package retention
+
+import (
+	"time"
+
+	"golift.io/rotatorr"
+)
+
+// Period is the size of the buckets in a Tier.
+type Period uint8
+
+// These are the periods a Tier may use. Buckets start at the top of the hour,
+// midnight, Monday, the first of the month, and the first of the year.
+const (
+	Hourly Period = iota
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+// Tier keeps the newest backup in every Period for the Keep duration. A Keep of zero means forever.
+type Tier struct {
+	Period Period
+	Keep   time.Duration
+}
+
+// Tiered is a grandfather-father-son policy. A backup is kept if it's the newest backup in its
+// bucket for any tier; the rest are deleted. Bucket boundaries use each backup's time zone. Backup
+// times from timerotator are parsed from the file names. This example keeps every hourly backup
+// for 2 days, one per day for 30 days, and one per month for a year:
+//
+//	retention.Tiered{
+//		{Period: retention.Hourly, Keep: 48 * time.Hour},
+//		{Period: retention.Daily, Keep: 30 * 24 * time.Hour},
+//		{Period: retention.Monthly, Keep: 365 * 24 * time.Hour},
+//	}
+type Tiered []Tier
+
+// Expired satisfies the rotatorr.Retention interface.
+func (t Tiered) Expired(backups []*rotatorr.Backup) []*rotatorr.Backup {
+	if len(t) == 0 {
+		return nil
+	}
+
+	keep := make(map[*rotatorr.Backup]bool)
+
+	for _, tier := range t {
+		newest := make(map[int64]*rotatorr.Backup)
+
+		for _, backup := range backups { // oldest first, so the last one in a bucket is the newest.
+			if tier.Keep <= 0 || time.Since(backup.Time) < tier.Keep {
+				newest[tier.Period.start(backup.Time).Unix()] = backup
+			}
+		}
+
+		for _, backup := range newest {
+			keep[backup] = true
+		}
+	}
+
+	return filter(backups, func(backup *rotatorr.Backup) bool { return !keep[backup] })
+}
+
+// start returns the beginning of the bucket a time belongs in.
+func (p Period) start(when time.Time) time.Time {
+	year, month, day := when.Date()
+
+	switch p {
+	case Daily:
+		return time.Date(year, month, day, 0, 0, 0, 0, when.Location())
+	case Weekly:
+		monday := day - (int(when.Weekday())+6)%7 //nolint:mnd // days since Monday.
+		return time.Date(year, month, monday, 0, 0, 0, 0, when.Location())
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, when.Location())
+	case Yearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, when.Location())
+	case Hourly:
+		fallthrough
+	default:
+		return time.Date(year, month, day, when.Hour(), 0, 0, 0, when.Location())
+	}
+}
+
+// Our type must satisfy a rotatorr.Retention.
+var _ rotatorr.Retention = Tiered(nil)
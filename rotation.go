@@ -0,0 +1,116 @@
+package rotatorr
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reason is why a log file was rotated.
+type Reason uint8
+
+// These are the reasons a log file gets rotated.
+const (
+	ReasonManual   Reason = iota // Rotate() was called.
+	ReasonSize                   // The file reached Config.FileSize.
+	ReasonAge                    // The file is older than Config.Every.
+	ReasonSchedule               // The Config.Schedule timer fired.
+)
+
+// Rotation describes a completed log file rotation.
+type Rotation struct {
+	OldFile string    // The active log file, Config.Filepath.
+	NewFile string    // The backup file name returned by the Rotatorr.
+	Size    int64     // Size of the rotated file.
+	Reason  Reason    // Why the file was rotated.
+	Time    time.Time // When the file was rotated.
+}
+
+// String turns a Reason into a word.
+func (r Reason) String() string {
+	switch r {
+	case ReasonManual:
+		return "manual"
+	case ReasonSize:
+		return "size"
+	case ReasonAge:
+		return "age"
+	case ReasonSchedule:
+		return "schedule"
+	default:
+		return "unknown"
+	}
+}
+
+// LastRotation returns the most recent rotation, or nil if the log has not rotated.
+// A new Rotation is created for every rotation; the returned value is never modified.
+func (l *Logger) LastRotation() *Rotation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.last
+}
+
+// RotateNow forces the log to rotate immediately, like Rotate, and returns this rotation.
+// Returns nil without an error when the active file was truncated instead of rotated,
+// because free disk space is low and LowSpace is LowSpaceActiveOnly.
+func (l *Logger) RotateNow() (*Rotation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, ErrClosed
+	}
+
+	last := l.last
+
+	if _, err := l.rotate(ReasonManual); err != nil {
+		return nil, err
+	}
+
+	if l.last == last {
+		return nil, nil //nolint:nilnil // the file was truncated, so there is no rotation.
+	}
+
+	return l.last, nil
+}
+
+// Backups returns the rotated backup files for the log, oldest first.
+// Returns nil if the Rotatorr does not satisfy the Lister interface.
+func (l *Logger) Backups() ([]string, error) {
+	lister, ok := l.config.Rotatorr.(Lister)
+	if !ok {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files, err := lister.Backups(l.config.Filepath)
+	if err != nil {
+		return nil, fmt.Errorf("listing backup files: %w", err)
+	}
+
+	return files, nil
+}
+
+// rotated records a successful rotation, and reports every rotation to the event callback.
+func (l *Logger) rotated(newFile string, size int64, reason Reason, err error) {
+	if err == nil {
+		l.last = &Rotation{
+			OldFile: l.config.Filepath,
+			NewFile: newFile,
+			Size:    size,
+			Reason:  reason,
+			Time:    time.Now(),
+		}
+	}
+
+	l.event(&Event{
+		Type:    EventPostRotate,
+		File:    l.config.Filepath,
+		NewFile: newFile,
+		Size:    size,
+		Reason:  reason,
+		Err:     err,
+	})
+}
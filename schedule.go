@@ -0,0 +1,91 @@
+package rotatorr
+
+import "time"
+
+// day is the length of a day, ignoring daylight saving time changes.
+const day = 24 * time.Hour
+
+// Schedule decides when to rotate the log file on wall-clock boundaries.
+// This matches the Schedule interface in github.com/robfig/cron, so a parsed
+// cron spec from that library may be used as a Schedule directly.
+type Schedule interface {
+	// Next returns the next time the log should rotate after the provided time.
+	// A zero time, or one that is not after the provided time, ends scheduled rotations.
+	Next(after time.Time) time.Time
+}
+
+// ScheduleFunc allows using a plain function as a Schedule.
+type ScheduleFunc func(after time.Time) time.Time
+
+// Aligned is a Schedule that rotates on multiples of Interval counted from midnight.
+// An Interval of time.Hour rotates at the top of every hour, and an Interval of
+// 24 hours (or zero) rotates at midnight. Intervals that do not divide a day evenly
+// restart at midnight. Intervals longer than a day rotate at midnight every N days.
+type Aligned struct {
+	Interval time.Duration
+	Location *time.Location // Time zone for midnight. Default: time.Local
+}
+
+// Next satisfies the Schedule interface.
+func (f ScheduleFunc) Next(after time.Time) time.Time {
+	return f(after)
+}
+
+// Next satisfies the Schedule interface.
+func (a *Aligned) Next(after time.Time) time.Time {
+	loc := a.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	after = after.In(loc)
+	midnight := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, loc)
+	tomorrow := midnight.AddDate(0, 0, 1)
+
+	if a.Interval <= 0 || a.Interval >= day {
+		return midnight.AddDate(0, 0, max(1, int(a.Interval/day)))
+	}
+
+	if next := midnight.Add((after.Sub(midnight)/a.Interval + 1) * a.Interval); next.Before(tomorrow) {
+		return next
+	}
+
+	return tomorrow
+}
+
+// newScheduleTimer returns a timer channel that fires at the next scheduled rotation,
+// and a function to reset (true) or stop (false) the timer. The channel is nil and
+// never fires when there is no Schedule. When Next returns a zero time (like a cron
+// spec that never matches), or a time that is not after now, there are no more
+// rotations, and the timer is stopped instead.
+func (l *Logger) newScheduleTimer() (<-chan time.Time, func(reset bool)) {
+	if l.config.Schedule == nil {
+		return nil, func(bool) {}
+	}
+
+	timer := time.NewTimer(time.Hour)
+	resetTimer := func(reset bool) {
+		if !reset {
+			timer.Stop()
+			return
+		}
+
+		now := time.Now()
+		if next := l.config.Schedule.Next(now); next.After(now) {
+			timer.Reset(next.Sub(now))
+			return
+		}
+
+		timer.Stop() // no more rotations.
+	}
+
+	resetTimer(true)
+
+	return timer.C, resetTimer
+}
+
+// Our types must satisfy a Schedule.
+var (
+	_ Schedule = (*Aligned)(nil)
+	_ Schedule = ScheduleFunc(nil)
+)
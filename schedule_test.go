@@ -0,0 +1,31 @@
+package rotatorr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golift.io/rotatorr"
+)
+
+func TestAligned(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	loc := time.FixedZone("test", -7*3600)
+	now := time.Date(2026, 10, 16, 13, 27, 10, 0, loc)
+
+	hourly := &rotatorr.Aligned{Interval: time.Hour, Location: loc}
+	assert.Equal(time.Date(2026, 10, 16, 14, 0, 0, 0, loc), hourly.Next(now), "hourly must rotate at the top of the hour")
+
+	daily := &rotatorr.Aligned{Location: loc}
+	assert.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, loc), daily.Next(now), "daily must rotate at midnight")
+
+	odd := &rotatorr.Aligned{Interval: 7 * time.Hour, Location: loc}
+	assert.Equal(time.Date(2026, 10, 16, 14, 0, 0, 0, loc), odd.Next(now))
+	assert.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, loc), odd.Next(now.Add(8*time.Hour)),
+		"intervals that do not divide a day must restart at midnight")
+
+	weekly := &rotatorr.Aligned{Interval: 7 * 24 * time.Hour, Location: loc}
+	assert.Equal(time.Date(2026, 10, 23, 0, 0, 0, 0, loc), weekly.Next(now))
+}
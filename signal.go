@@ -0,0 +1,89 @@
+package rotatorr
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// SignalAction is what HandleSignals does when a signal is received.
+type SignalAction uint8
+
+// These are the actions HandleSignals may take for a signal.
+const (
+	// SignalRotate calls Logger.Rotate.
+	SignalRotate SignalAction = iota
+	// SignalReopen calls Logger.Reopen. Use this after logrotate moves the file.
+	SignalReopen
+)
+
+// SignalResult is passed to the HandleSignals callback after each signal is handled.
+type SignalResult struct {
+	Signal os.Signal
+	Action SignalAction
+	Size   int64 // Size of the rotated file. Only set by SignalRotate.
+	Err    error
+}
+
+// String returns the name of a signal action.
+func (a SignalAction) String() string {
+	switch a {
+	case SignalRotate:
+		return "rotate"
+	case SignalReopen:
+		return "reopen"
+	default:
+		return "unknown"
+	}
+}
+
+// HandleSignals rotates or reopens a Logger when the provided OS signals are received.
+// This blocks until the context is cancelled, so run it in a go routine. The report
+// callback is optional, and is called after every signal is handled. Example, that
+// works with a logrotate postrotate script that sends SIGHUP:
+//
+//	go rotatorr.HandleSignals(ctx, logger, map[os.Signal]rotatorr.SignalAction{
+//		syscall.SIGHUP:  rotatorr.SignalReopen,
+//		syscall.SIGUSR1: rotatorr.SignalRotate,
+//	}, nil)
+func HandleSignals(
+	ctx context.Context,
+	logger *Logger,
+	actions map[os.Signal]SignalAction,
+	report func(result *SignalResult),
+) {
+	if len(actions) == 0 {
+		return // signal.Notify catches every signal when none are provided.
+	}
+
+	signals := make([]os.Signal, 0, len(actions))
+	for sig := range actions {
+		signals = append(signals, sig)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, signals...)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigChan:
+			result := &SignalResult{Signal: sig, Action: actions[sig]}
+
+			switch result.Action {
+			case SignalReopen:
+				result.Err = logger.Reopen()
+			case SignalRotate:
+				fallthrough
+			default:
+				result.Size, result.Err = logger.Rotate()
+			}
+
+			if report != nil {
+				report(result)
+			}
+		}
+	}
+}
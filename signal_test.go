@@ -0,0 +1,82 @@
+package rotatorr_test
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/introtator"
+)
+
+func TestHandleSignals(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows cannot send signals to itself")
+	}
+
+	// Catch the signal here too, so it cannot kill the test before HandleSignals is listening.
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGHUP)
+	defer signal.Stop(caught)
+
+	dir := t.TempDir()
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "signal.log"),
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	_, err = logger.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	results := make(chan *rotatorr.SignalResult, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		rotatorr.HandleSignals(ctx, logger, map[os.Signal]rotatorr.SignalAction{
+			syscall.SIGHUP: rotatorr.SignalRotate,
+		}, func(result *rotatorr.SignalResult) {
+			select {
+			case results <- result:
+			default:
+			}
+		})
+	}()
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+
+	var result *rotatorr.SignalResult
+
+	for result == nil { // Keep sending until HandleSignals is listening.
+		require.NoError(t, process.Signal(syscall.SIGHUP))
+
+		select {
+		case result = <-results:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+
+	require.NoError(t, result.Err)
+	assert.Equal(syscall.SIGHUP, result.Signal)
+	assert.Equal(rotatorr.SignalRotate, result.Action)
+	assert.EqualValues(6, result.Size, "the first rotation must contain the write")
+	assert.FileExists(filepath.Join(dir, "signal.1.log"))
+}
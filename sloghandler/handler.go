@@ -0,0 +1,145 @@
+// Package sloghandler provides a log/slog Handler that writes JSON or text records
+// to a rotating rotatorr.Logger. Every record is written with a single Write call,
+// so each record lands whole in one log file. The exception is a record larger than
+// Config.FileSize when the Logger is configured to split large writes.
+//
+// Optionally, a record describing the previous rotation (old name, new name,
+// size and reason) is written into each new log file.
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golift.io/rotatorr"
+)
+
+// Format is the output format for log records.
+type Format uint8
+
+// These are the supported record formats.
+const (
+	JSON Format = iota // Use slog.JSONHandler. This is the default.
+	Text               // Use slog.TextHandler.
+)
+
+// RotatedMsg is the message on records that describe a rotation.
+const RotatedMsg = "log file rotated"
+
+// Options control the handler's output. All of the struct members are optional.
+type Options struct {
+	slog.HandlerOptions
+
+	Format Format
+	// RotationRecords writes a record describing the previous rotation into each new log file.
+	// The record is written before the next record, or right after it if that record caused
+	// the rotation. The record is written at info level without any groups or attributes.
+	RotationRecords bool
+}
+
+// Handler is a slog.Handler that writes to a rotatorr.Logger.
+type Handler struct {
+	slog.Handler
+
+	root   slog.Handler // writes rotation records; has no groups or attributes.
+	logger *rotatorr.Logger
+	opts   *Options
+	state  *state // shared with derived handlers.
+}
+
+// state tracks the last rotation a record was written for.
+type state struct {
+	sync.Mutex
+
+	seen *rotatorr.Rotation
+}
+
+// New returns a slog.Handler that writes to the provided Logger.
+// Pass nil options to write JSON records with the slog defaults.
+func New(logger *rotatorr.Logger, opts *Options) *Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var handler slog.Handler
+
+	switch opts.Format {
+	case Text:
+		handler = slog.NewTextHandler(logger, &opts.HandlerOptions)
+	case JSON:
+		fallthrough
+	default:
+		handler = slog.NewJSONHandler(logger, &opts.HandlerOptions)
+	}
+
+	return &Handler{
+		Handler: handler,
+		root:    handler,
+		logger:  logger,
+		opts:    opts,
+		state:   &state{seen: logger.LastRotation()},
+	}
+}
+
+// Handle writes a record to the Logger. Satisfies the slog.Handler interface.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.opts.RotationRecords {
+		return h.Handler.Handle(ctx, record) //nolint:wrapcheck
+	}
+
+	h.state.Lock()
+	defer h.state.Unlock()
+
+	h.rotated(ctx) // rotated by the timer or by hand.
+
+	err := h.Handler.Handle(ctx, record)
+
+	h.rotated(ctx) // rotated by writing this record.
+
+	return err //nolint:wrapcheck
+}
+
+// WithAttrs satisfies the slog.Handler interface.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(h.Handler.WithAttrs(attrs))
+}
+
+// WithGroup satisfies the slog.Handler interface.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return h.with(h.Handler.WithGroup(name))
+}
+
+func (h *Handler) with(handler slog.Handler) *Handler {
+	return &Handler{
+		Handler: handler,
+		root:    h.root,
+		logger:  h.logger,
+		opts:    h.opts,
+		state:   h.state,
+	}
+}
+
+// rotated writes a rotation record if the Logger rotated since the last one was written.
+func (h *Handler) rotated(ctx context.Context) {
+	last := h.logger.LastRotation()
+	if last == nil || last == h.state.seen {
+		return
+	}
+
+	h.state.seen = last
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, RotatedMsg, 0)
+	record.AddAttrs(
+		slog.String("old_file", last.OldFile),
+		slog.String("new_file", last.NewFile),
+		slog.Int64("size", last.Size),
+		slog.String("reason", last.Reason.String()),
+		slog.Time("rotated", last.Time),
+	)
+
+	_ = h.root.Handle(ctx, record) // the next record finds the same error.
+}
+
+// Our Handler must satisfy a slog.Handler.
+var _ slog.Handler = (*Handler)(nil)
@@ -0,0 +1,83 @@
+package sloghandler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golift.io/rotatorr"
+	"golift.io/rotatorr/introtator"
+	"golift.io/rotatorr/sloghandler"
+)
+
+// readRecords returns every JSON record in a file, and fails if a line is not a whole record.
+func readRecords(t *testing.T, fileName string) []map[string]any {
+	t.Helper()
+
+	file, err := os.Open(fileName)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	var records []map[string]any
+
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "every line must be a whole record")
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	logger, err := rotatorr.New(&rotatorr.Config{
+		Filepath: filepath.Join(dir, "slog.log"),
+		FileSize: 500,
+		Rotatorr: &introtator.Layout{},
+	})
+	require.NoError(t, err)
+
+	defer logger.Close()
+
+	log := slog.New(sloghandler.New(logger, &sloghandler.Options{RotationRecords: true})).
+		With("app", "test").WithGroup("data")
+
+	idx := 0
+	for ; logger.LastRotation() == nil; idx++ {
+		log.Info("this is a test log message", "idx", idx)
+	}
+
+	// The last record rotated the file.
+	backup := readRecords(t, filepath.Join(dir, "slog.1.log"))
+	assert.Len(backup, idx-1)
+
+	active := readRecords(t, filepath.Join(dir, "slog.log"))
+	require.Len(t, active, 2)
+	assert.Equal("this is a test log message", active[0]["msg"])
+	assert.Equal(map[string]any{"idx": float64(idx - 1)}, active[0]["data"], "groups must be kept")
+	assert.Equal(sloghandler.RotatedMsg, active[1]["msg"])
+	assert.Equal(filepath.Join(dir, "slog.1.log"), active[1]["new_file"])
+	assert.Equal("size", active[1]["reason"])
+	assert.Nil(active[1]["app"], "rotation records must not have attributes")
+	// A manual rotation must be recorded before the next record.
+	_, err = logger.Rotate()
+	require.NoError(t, err)
+	log.Info("after rotate")
+
+	active = readRecords(t, filepath.Join(dir, "slog.log"))
+	require.Len(t, active, 2)
+	assert.Equal(sloghandler.RotatedMsg, active[0]["msg"])
+	assert.Equal("manual", active[0]["reason"])
+	assert.Equal("after rotate", active[1]["msg"])
+}
@@ -0,0 +1,98 @@
+package rotatorr
+
+import (
+	"maps"
+	"time"
+)
+
+// Stats is a snapshot of a Logger's state and counters. Get one from Logger.Stats().
+// The counters start at zero when the Logger is created.
+type Stats struct {
+	FilePath     string            // The active log file, Config.Filepath.
+	FileSize     int64             // Size of the active log file, including buffered data.
+	FileAge      time.Duration     // Time since the active log file was created.
+	Writes       uint64            // Number of calls to Write.
+	Bytes        uint64            // Number of bytes written.
+	Rotations    map[Reason]uint64 // Number of successful rotations, by reason.
+	LastRotation time.Time         // When the log file was last rotated.
+	OpenErrors   uint64            // Number of times the log file could not be opened.
+	WriteErrors  uint64            // Number of times writing or syncing the log file failed.
+	RotateErrors uint64            // Number of times the Rotatorr failed to rotate.
+	DeleteErrors uint64            // Number of times retention failed to delete a backup.
+	Deletes      uint64            // Number of backup files deleted by retention.
+	LastError    error             // The most recent error.
+	LastErrorAt  time.Time         // When the most recent error happened.
+	RotateTime   time.Duration     // Total time spent in Rotatorr.Rotate.
+	PostTime     time.Duration     // Total time spent in Rotatorr.Post.
+}
+
+// Stats returns a snapshot of the Logger's state and counters.
+func (l *Logger) Stats() *Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.Rotations = maps.Clone(l.stats.Rotations)
+	stats.FilePath = l.config.Filepath
+	stats.FileSize = l.size
+
+	if stats.Rotations == nil {
+		stats.Rotations = map[Reason]uint64{}
+	}
+
+	if l.File != nil {
+		stats.FileAge = time.Since(l.created)
+	}
+
+	return &stats
+}
+
+// count updates the counters from an event.
+func (s *Stats) count(event *Event) {
+	if event.Err != nil {
+		s.LastError = event.Err
+		s.LastErrorAt = event.Time
+	}
+
+	switch event.Type {
+	case EventPostRotate:
+		if event.Err != nil {
+			s.RotateErrors++
+			return
+		}
+
+		if s.Rotations == nil {
+			s.Rotations = map[Reason]uint64{}
+		}
+
+		s.Rotations[event.Reason]++
+		s.LastRotation = event.Time
+	case EventOpenError:
+		s.OpenErrors++
+	case EventWriteError:
+		s.WriteErrors++
+	case EventDelete:
+		if event.Err != nil {
+			s.DeleteErrors++
+		} else {
+			s.Deletes++
+		}
+	case EventPreRotate, EventLowSpace:
+	}
+}
+
+// rotatorrRotate calls the Rotatorr's Rotate method and times it.
+func (l *Logger) rotatorrRotate() (string, error) {
+	start := time.Now()
+	defer func() { l.stats.RotateTime += time.Since(start) }()
+
+	return l.Interface.Rotate(l.config.Filepath) //nolint:wrapcheck // the callers wrap it.
+}
+
+// rotatorrPost calls the Rotatorr's Post method and times it.
+func (l *Logger) rotatorrPost(newFile string) {
+	start := time.Now()
+	defer func() { l.stats.PostTime += time.Since(start) }()
+
+	l.Interface.Post(l.config.Filepath, newFile)
+}
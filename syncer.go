@@ -0,0 +1,114 @@
+package rotatorr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncPolicy controls when the active log file is flushed to stable storage with fsync.
+type SyncPolicy uint8
+
+// These are the available sync policies. All of them except SyncNever also sync
+// the file before it is rotated or closed, and sync the directories after a rotation.
+const (
+	SyncNever         SyncPolicy = iota // Leave it to the operating system. This is the default.
+	SyncEveryWrite                      // Sync after every write. Slow; flushes the buffer every write.
+	SyncEveryBytes                      // Sync after Config.SyncSize bytes are written.
+	SyncEveryInterval                   // Sync every Config.SyncInterval.
+	SyncOnRotate                        // Sync only before rotating or closing the file.
+)
+
+// Sync flushes the buffer and commits the active log file to stable storage.
+// This is serialized with writes, and makes Logger satisfy a WriteSyncer interface.
+func (l *Logger) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	return l.sync()
+}
+
+// sync flushes the buffer and fsyncs the active file.
+func (l *Logger) sync() error {
+	err := l.flush()
+	if err != nil || l.File == nil {
+		return err
+	}
+
+	l.unsynced = 0
+
+	if err = l.File.Sync(); err != nil {
+		err = fmt.Errorf("syncing log file %s: %w", l.config.Filepath, err)
+		l.event(&Event{Type: EventWriteError, File: l.config.Filepath, Err: err})
+
+		return err
+	}
+
+	return nil
+}
+
+// syncAfterWrite syncs the active file if the sync policy calls for it after a write.
+func (l *Logger) syncAfterWrite() error {
+	switch l.config.SyncPolicy {
+	case SyncEveryWrite:
+		return l.sync()
+	case SyncEveryBytes:
+		if l.unsynced >= l.config.SyncSize {
+			return l.sync()
+		}
+	case SyncNever, SyncEveryInterval, SyncOnRotate:
+	}
+
+	return nil
+}
+
+// syncDirs fsyncs the directories holding the provided files so renames survive a crash.
+// This is best-effort; not every platform (windows) allows syncing a directory.
+func (l *Logger) syncDirs(fileNames ...string) {
+	synced := make(map[string]struct{})
+
+	for _, fileName := range fileNames {
+		if fileName == "" {
+			continue
+		}
+
+		dir := filepath.Dir(fileName)
+		if _, ok := synced[dir]; ok {
+			continue
+		}
+
+		synced[dir] = struct{}{}
+
+		dirFile, err := l.OpenFile(dir, os.O_RDONLY, 0)
+		if err != nil {
+			continue
+		}
+
+		_ = dirFile.Sync()
+		_ = dirFile.Close()
+	}
+}
+
+// newTicker returns a ticker channel and its stop function. When not enabled,
+// the returned channel is nil and never fires.
+func newTicker(interval time.Duration, enabled bool) (<-chan time.Time, func()) {
+	if !enabled || interval <= 0 {
+		return nil, func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+
+	return ticker.C, ticker.Stop
+}
+
+// Our interface must satify a WriteSyncer, like the one zap uses.
+var _ interface {
+	io.Writer
+	Sync() error
+} = (*Logger)(nil)
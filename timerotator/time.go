@@ -4,9 +4,12 @@
 // By default rotated log files are named: service-2006-01-02T15-04-05.000.log.
 // Control the time format with the Layout.Format parameter. The defaults in this
 // package work very similarly to: https://github.com/natefinch/lumberjack
+// The time stamps are passed to the Retention policy, so a retention.Tiered
+// (grandfather-father-son) policy keeps the newest backup in every hour, day or month.
 package timerotator
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -14,7 +17,9 @@ import (
 	"time"
 
 	"golift.io/rotatorr"
+	"golift.io/rotatorr/compressor"
 	"golift.io/rotatorr/filer"
+	"golift.io/rotatorr/internal/backups"
 )
 
 // Layout defines how time-stamped backup logs have their file names decided.
@@ -24,11 +29,24 @@ type Layout struct {
 	ArchiveDir string        // Location where rotated backup logs are moved to.
 	FileCount  int           // Maximum number of rotated log files.
 	FileAge    time.Duration // Maximum age of rotated files.
-	UseUTC     bool          // Sets the time zone to UTC when writing Time Formats (backup files).
-	Format     string        // Format for Go Time. Used as the name.
-	Joiner     string        // The string betwene the file name prefix and time stamp. Default: -
+	TotalSize  int64         // Maximum total size of rotated files (compressed or not), in bytes.
+	// Retention is a custom policy applied with FileCount, FileAge and TotalSize. Any of them
+	// may delete a file. Setting this stats every backup file on rotation to get its size.
+	Retention rotatorr.Retention
+	UseUTC    bool   // Sets the time zone to UTC when writing Time Formats (backup files).
+	Format    string // Format for Go Time. Used as the name.
+	Joiner    string // The string betwene the file name prefix and time stamp. Default: -
+	Extension string // Backup file extension, like ".jsonl". Default: the log file's extension.
+	// Recover passes backups that lack a compression suffix to PostRotate when Dirs is called at
+	// startup. This finishes compressions that were queued or running when the app exited.
+	// Only set this when PostRotate compresses files.
+	Recover bool
+	// Background is the background work started by PostRotate, like a compressor.Group or
+	// compressor.Pool. Wait waits for it, so Logger.Shutdown waits for this Logger's compressions.
+	Background rotatorr.Waiter
 	// Mockable interfaces. Can be used for custom processing. Setting these is very optional.
 	PostRotate func(fileName, newFile string)
+	deleted    func(fileName string, err error) // set by NotifyDelete.
 }
 
 // Some Formats you may use in your app.
@@ -38,20 +56,62 @@ const (
 	FormatDumbUSA = "02-01-2006_15:04:05"     // Example: Silly Americans.
 )
 
-// Some constant this package uses; not really needed externally.
+// DefaultJoiner is used when Layout.Joiner is empty.
+const DefaultJoiner = "-"
+
+// These were the only backup file extensions before Layout.Extension and compressor codecs.
+//
+// Deprecated: The extension comes from Layout.Extension or the log file name, and
+// every suffix registered with the compressor package is recognized.
 const (
-	LogExt        = ".log"
-	DefaultJoiner = "-"
-	GZext         = ".gz"
+	LogExt = ".log"
+	GZext  = ".gz"
 )
 
 // Post satisfies the Rotatorr interface.
 func (l *Layout) Post(fileName, newFile string) {
-	if l.PostRotate != nil {
-		l.PostRotate(fileName, newFile)
+	l.config().Post(fileName, newFile)
+}
+
+// NotifyDelete satisfies the rotatorr.DeleteNotifier interface.
+// The hook is called after every attempt to delete an old backup file.
+func (l *Layout) NotifyDelete(hook func(fileName string, err error)) {
+	l.deleted = hook
+}
+
+// config returns the settings shared with the other layouts.
+func (l *Layout) config() *backups.Config {
+	return &backups.Config{
+		Filer:      l.Filer,
+		FileCount:  l.FileCount,
+		FileAge:    l.FileAge,
+		TotalSize:  l.TotalSize,
+		Retention:  l.Retention,
+		Recover:    l.Recover,
+		Background: l.Background,
+		PostRotate: l.PostRotate,
+		Deleted:    l.deleted,
 	}
 }
 
+// Backups satisfies the rotatorr.Lister interface.
+// Returns the backup files for a log file, oldest first.
+func (l *Layout) Backups(fileName string) ([]string, error) {
+	return l.getAllLogFiles(fileName).Files, nil
+}
+
+// Prune satisfies the rotatorr.Pruner interface.
+// Deletes backup files, oldest first, until enough returns true.
+func (l *Layout) Prune(fileName string, enough func() bool) error {
+	return l.config().Prune(l.getAllLogFiles(fileName).Files, enough) //nolint:wrapcheck
+}
+
+// Wait satisfies the rotatorr.Waiter interface. This waits for the Background work
+// started by PostRotate, so Logger.Shutdown can wait for it to finish.
+func (l *Layout) Wait(ctx context.Context) error {
+	return l.config().Wait(ctx) //nolint:wrapcheck
+}
+
 // Rotate forces the log to rotate immediately. Returns the size of the rotated log.
 func (l *Layout) Rotate(fileName string) (string, error) {
 	now := time.Now()
@@ -61,7 +121,7 @@ func (l *Layout) Rotate(fileName string) (string, error) {
 
 	var (
 		dir     = l.getArchiveDir(fileName)
-		newFile = filepath.Join(dir, l.getPrefix(fileName)+now.Format(l.Format)+LogExt)
+		newFile = filepath.Join(dir, l.getPrefix(fileName)+now.Format(l.Format)+l.getExt(fileName))
 	)
 
 	err := l.Rename(fileName, newFile)
@@ -86,79 +146,42 @@ func (l *Layout) Dirs(fileName string) ([]string, error) {
 		l.Filer = filer.Default()
 	}
 
-	switch fpath := filepath.Dir(fileName); {
-	case l.ArchiveDir == "" || fpath == l.ArchiveDir:
-		return []string{fpath}, nil
-	default:
-		return []string{fpath, l.ArchiveDir}, nil
-	}
+	l.config().Start(l.getArchiveDir(fileName), fileName, l, l.isBackup(fileName))
+
+	return backups.Dirs(fileName, l.ArchiveDir), nil
 }
 
 func (l *Layout) getArchiveDir(fileName string) string {
-	if l.ArchiveDir != "" {
-		return l.ArchiveDir
-	}
-
-	return filepath.Dir(fileName)
+	return backups.ArchiveDir(fileName, l.ArchiveDir)
 }
 
-// deleteOldLogs deletes any files that are older than FileAge (if Format=Time).
-// Then it deletes extra logs if we're over our NumFiles count.
+// deleteOldLogs deletes the backup files chosen by the retention policy.
+// Times come from the file names.
 func (l *Layout) deleteOldLogs(logFiles *backupFiles) error {
-	gone := make(map[string]struct{})
-
-	if l.FileAge > 0 {
-		// Parse the time stamp out of each file name.
-		// If the time is older than FileAge, delete the file.
-		for idx, when := range logFiles.value {
-			if time.Since(when) < l.FileAge {
-				continue
-			}
-
-			err := l.Remove(logFiles.Files[idx])
-			if err != nil {
-				return fmt.Errorf("error removing file: %w", err)
-			}
-
-			gone[logFiles.Files[idx]] = struct{}{}
-		}
-	}
+	config := l.config()
 
-	count := len(logFiles.Files) - len(gone)
-
-	if l.FileCount > 0 {
-		for _, fileName := range logFiles.Files {
-			if count <= l.FileCount {
-				return nil
-			}
-
-			if _, ok := gone[fileName]; ok {
-				continue // already deleted this one.
-			}
-
-			err := l.Remove(fileName)
-			if err != nil {
-				return fmt.Errorf("error removing file: %w", err)
-			}
-
-			count--
-		}
-	}
-
-	return nil
+	return config.Delete(config.Policy().Expired(config.Backups(logFiles.Files, logFiles.value))) //nolint:wrapcheck
 }
 
 // getPrefix returns the expected - or created - prefix on our log files.
+// The log file's extension is removed; backups get getExt instead.
 func (l *Layout) getPrefix(fileName string) string {
-	return strings.TrimSuffix(filepath.Base(fileName), LogExt) + l.Joiner
+	return backups.Prefix(fileName, l.Joiner)
+}
+
+// getExt returns the extension for backup files: Extension, or the log file's extension.
+func (l *Layout) getExt(fileName string) string {
+	return backups.Ext(fileName, l.Extension)
 }
 
 // getAllLogFiles finds all the backup log files that match our Time Format.
+// Time stamps are parsed in the same time zone they were written in.
 func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 	var (
 		list   = &backupFiles{Files: []string{}, value: []time.Time{}}
 		dir    = l.getArchiveDir(fileName)
 		prefix = l.getPrefix(fileName)
+		ext    = l.getExt(fileName)
 	)
 
 	fileList, err := l.ReadDir(dir)
@@ -168,14 +191,7 @@ func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 
 	for idx := range fileList {
 		name := fileList[idx].Name()
-		if !strings.HasPrefix(name, prefix) {
-			continue // not our file.
-		}
-
-		part := strings.TrimSuffix(strings.TrimPrefix(name, prefix), GZext)
-
-		t, err := time.Parse(l.Format, strings.TrimSuffix(part, LogExt))
-		if err == nil { // if err != nil, then not our file.
+		if t, ok := l.parse(name, prefix, ext); ok { // if !ok, then not our file.
 			list.Files = append(list.Files, filepath.Join(dir, name))
 			list.value = append(list.value, t)
 		}
@@ -187,5 +203,39 @@ func (l *Layout) getAllLogFiles(fileName string) *backupFiles {
 	return list
 }
 
-// Our interface must satify a rotatorr.Rotatorr.
-var _ rotatorr.Rotatorr = (*Layout)(nil)
+// parse returns the time stamp in a backup file name, and false if the name is not one of our backups.
+func (l *Layout) parse(name, prefix, ext string) (time.Time, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return time.Time{}, false // not our file.
+	}
+
+	location := time.Local
+	if l.UseUTC {
+		location = time.UTC
+	}
+
+	part := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressor.Suffix(name))
+
+	t, err := time.ParseInLocation(l.Format, strings.TrimSuffix(part, ext), location)
+
+	return t, err == nil
+}
+
+// isBackup returns a function that reports if a file name, without a path, is a backup of fileName.
+func (l *Layout) isBackup(fileName string) func(name string) bool {
+	prefix, ext := l.getPrefix(fileName), l.getExt(fileName)
+
+	return func(name string) bool {
+		_, ok := l.parse(name, prefix, ext)
+		return ok
+	}
+}
+
+// Our interface must satify a rotatorr.Rotatorr and the optional interfaces.
+var (
+	_ rotatorr.Rotatorr       = (*Layout)(nil)
+	_ rotatorr.Waiter         = (*Layout)(nil)
+	_ rotatorr.DeleteNotifier = (*Layout)(nil)
+	_ rotatorr.Lister         = (*Layout)(nil)
+	_ rotatorr.Pruner         = (*Layout)(nil)
+)
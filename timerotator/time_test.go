@@ -10,8 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
+	"golift.io/rotatorr/compressor"
 	"golift.io/rotatorr/filer"
 	"golift.io/rotatorr/mocks"
+	"golift.io/rotatorr/retention"
 	"golift.io/rotatorr/timerotator"
 )
 
@@ -136,3 +138,124 @@ func TestRotateDelete(t *testing.T) {
 	assert.Equal(newName, file)
 	require.NoError(t, err)
 }
+
+func TestTotalSize(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.log")
+	layout := &timerotator.Layout{Format: timerotator.FormatNoSecnd, TotalSize: 25}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	for hours := range 4 {
+		backup := "service-" + now.Add(-time.Duration(hours+1)*time.Hour).Format(layout.Format) + ".log"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, backup), []byte("0123456789"), 0o600))
+	}
+
+	require.NoError(t, os.WriteFile(fileName, []byte("0123456789"), 0o600))
+	newFile, err := layout.Rotate(fileName)
+	require.NoError(t, err)
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{
+		filepath.Join(dir, "service-"+now.Add(-time.Hour).Format(layout.Format)+".log"),
+		newFile,
+	}, backups, "the oldest files must be deleted to stay under TotalSize")
+}
+
+func TestTiered(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.log")
+	layout := &timerotator.Layout{
+		Format:    timerotator.FormatNoSecnd,
+		Retention: retention.Tiered{{Period: retention.Daily}},
+	}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+
+	// The modification times are all now, so the policy must use the time stamps in the names.
+	for _, stamp := range []string{"2020-01-01T10-00-00", "2020-01-01T12-00-00", "2020-01-02T09-00-00"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "service-"+stamp+".log"), []byte("backup"), 0o600))
+	}
+
+	require.NoError(t, os.WriteFile(fileName, []byte("active"), 0o600))
+	newFile, err := layout.Rotate(fileName)
+	require.NoError(t, err)
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{
+		filepath.Join(dir, "service-2020-01-01T12-00-00.log"),
+		filepath.Join(dir, "service-2020-01-02T09-00-00.log"),
+		newFile,
+	}, backups, "only the newest backup per day must be kept")
+}
+
+func TestExtension(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "service.jsonl")
+	layout := &timerotator.Layout{}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service-2020-01-01T00-00-00.000.jsonl.gz"), nil, 0o600))
+	require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+
+	newFile, err := layout.Rotate(fileName)
+	require.NoError(t, err)
+	assert.Equal(".jsonl", filepath.Ext(newFile))
+
+	backups, err := layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{filepath.Join(dir, "service-2020-01-01T00-00-00.000.jsonl.gz"), newFile}, backups)
+
+	// The log file's extension must be replaced, not kept in the middle of the name.
+	fileName = filepath.Join(dir, "web.log")
+	layout = &timerotator.Layout{Extension: ".jsonl"}
+	_, err = layout.Dirs(fileName)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(fileName, []byte("data"), 0o600))
+
+	newFile, err = layout.Rotate(fileName)
+	require.NoError(t, err)
+	assert.Regexp(`^web-[0-9T.-]+\.jsonl$`, filepath.Base(newFile))
+
+	backups, err = layout.Backups(fileName)
+	require.NoError(t, err)
+	assert.Equal([]string{newFile}, backups)
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	var (
+		dir      = t.TempDir()
+		fileName = filepath.Join(dir, "service.log")
+		layout   = &timerotator.Layout{Recover: true, PostRotate: compressor.CompressPostRotate}
+		backup   = filepath.Join(dir, "service-2026-10-16T08-00-00.000.log")
+	)
+
+	for _, name := range []string{fileName, backup} {
+		require.NoError(t, os.WriteFile(name, []byte("data"), 0o600))
+	}
+
+	_, err := layout.Dirs(fileName)
+	require.NoError(t, err)
+	assert.FileExists(fileName, "the active file must never be compressed")
+	assert.NoFileExists(backup)
+	assert.FileExists(backup+compressor.SuffixGZ, "the backup must be compressed at startup")
+}